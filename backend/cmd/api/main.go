@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -12,11 +13,15 @@ import (
 	"reserve_game/internal/handlers"
 	"reserve_game/internal/middleware"
 	"reserve_game/internal/models"
+	"reserve_game/internal/payment"
 	"reserve_game/internal/repository"
 )
 
 func main() {
 	cfg := config.LoadConfig()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal("Invalid configuration: ", err)
+	}
 
 	// Connect to Database
 	db, err := gorm.Open(postgres.Open(cfg.DatabaseURL), &gorm.Config{})
@@ -24,16 +29,50 @@ func main() {
 		log.Fatal("Failed to connect to database:", err)
 	}
 
+	// Convert legacy JSON text columns before AutoMigrate touches them
+	if err := repository.MigratePositionColumns(db); err != nil {
+		log.Fatal("Failed to migrate position columns:", err)
+	}
+	if err := repository.MigrateRatingScope(db); err != nil {
+		log.Fatal("Failed to migrate player ratings:", err)
+	}
+	if err := repository.MigrateRefundIndex(db); err != nil {
+		log.Fatal("Failed to migrate refunds:", err)
+	}
+
 	// Migrate Schema
 	// Added waitlist order column if not exists by auto migrate
-	err = db.AutoMigrate(&models.User{}, &models.Match{}, &models.Booking{}, &models.Team{}, &models.TeamMember{}, &models.Sport{}, &models.SportPosition{}, &models.Club{}, &models.ClubMember{}, &models.Announcement{}, &models.Notification{})
+	err = db.AutoMigrate(&models.User{}, &models.Match{}, &models.MatchSeries{}, &models.MatchTemplate{}, &models.Venue{}, &models.Court{}, &models.SeriesSubscription{}, &models.Booking{}, &models.BookingHistory{}, &models.TeamLineup{}, &models.Team{}, &models.TeamMember{}, &models.TeamConstraint{}, &models.FixtureSchedule{}, &models.Fixture{}, &models.PlayerRating{}, &models.Sport{}, &models.SportPosition{}, &models.Club{}, &models.ClubMember{}, &models.Announcement{}, &models.Notification{}, &models.PaymentIntent{}, &models.PaymentOverride{}, &models.Refund{}, &models.WalletTransaction{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+	if err := repository.MigrateMatchStatuses(db); err != nil {
+		log.Fatal("Failed to migrate match statuses:", err)
+	}
+	if err := repository.MigrateLegacyTeams(db); err != nil {
+		log.Fatal("Failed to migrate legacy teams:", err)
+	}
+	if err := repository.MigrateVenueCoordinates(db); err != nil {
+		log.Fatal("Failed to migrate venue coordinates:", err)
+	}
+	if err := repository.MigrateBookingAmounts(db); err != nil {
+		log.Fatal("Failed to migrate booking amounts:", err)
+	}
 
 	// Initialize Layers
 	repo := repository.NewRepository(db)
-	handler := handlers.NewHandler(repo)
+	provider, err := payment.NewProvider(cfg.PaymentProvider, cfg.PaymentWebhookSecret, cfg.PublicBaseURL)
+	if err != nil {
+		log.Fatal("Failed to configure payment provider:", err)
+	}
+	handler := handlers.NewHandler(repo, provider)
+
+	// Hand unanswered waitlist offers on to the next player
+	handler.BookingService.StartOfferExpiryWorker(time.Minute)
+	// Close registration, start and complete matches on schedule
+	handler.BookingService.StartLifecycleWorker(time.Minute)
+	// Keep recurring series generated ahead
+	handler.SeriesService.StartGenerationWorker(time.Hour)
 
 	// Fix Data (Temporary for Dev)
 	if err := repo.FixData(); err != nil {
@@ -47,7 +86,7 @@ func main() {
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-User-ID"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Total-Count"},
 		AllowCredentials: true,
 	}))
 
@@ -65,28 +104,35 @@ func main() {
 		api.POST("/register", handler.Register)
 		api.POST("/login", handler.Login)
 
-	// Admin
-	admin := api.Group("/admin")
-	{
-		admin.GET("/users", handler.AdminGetAllUsers)
-		admin.GET("/sports", handler.GetMasterSports)
-	}
+		// Admin (platform role required)
+		handler.RegisterAdminRoutes(api)
 
-	// OAuth
-	api.GET("/oauth/login", handler.GoogleLogin)
-	api.GET("/oauth/callback", handler.GoogleCallback)
+		// OAuth
+		api.GET("/oauth/login", handler.GoogleLogin)
+		api.GET("/oauth/callback", handler.GoogleCallback)
 		api.GET("/matches", handler.ListMatches)
 		api.GET("/matches/:id", handler.GetMatch)
-		api.GET("/matches/:id/teams", handler.GetTeams)
+		api.GET("/matches/:id/teams", handler.GetTeams)          // Published lineup only
+		api.GET("/matches/:id/results", handler.GetMatchResults) // Fixtures and standings
 		api.GET("/master/sports", handler.GetMasterSports)
 
+		// Payment provider callbacks (signature verified)
+		api.POST("/payments/webhook", handler.PaymentWebhook)
+		if cfg.DevMode && provider.Name() == "fake" {
+			api.POST("/payments/fake/:ref/complete", handler.FakeCompletePayment) // Dev checkout page stand-in
+		}
+
 		// Clubs Public
 		api.GET("/clubs", handler.ListClubs)
 		api.GET("/clubs/:id", handler.GetClub)
+		api.GET("/clubs/:id/series", handler.ListClubSeries)
+		api.GET("/clubs/:id/venues", handler.ListClubVenues)
+		api.GET("/venues/:id", handler.GetVenue)
+		api.GET("/series/:id", handler.GetSeries)
 
 		// Protected
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware())
+		protected.Use(middleware.AuthMiddleware(), middleware.LoadUser(repo))
 		{
 			protected.POST("/upload", handler.UploadAvatar) // Upload Endpoint
 
@@ -94,11 +140,50 @@ func main() {
 			protected.POST("/matches", handler.CreateMatch)           // Create Match (Schedule)
 			protected.PUT("/matches/:id", handler.UpdateMatch)        // Reschedule / Edit (Draft)
 			protected.PUT("/matches/:id/cancel", handler.CancelMatch) // Cancel Match
-			protected.POST("/matches/:id/teams/generate", handler.GenerateTeams)
-			protected.PUT("/teams/members/:memberId", handler.UpdateTeamMember) // Manual move
+			protected.POST("/matches/:id/clone", handler.CloneMatch)  // Copy as a new draft
+			protected.POST("/clubs/:id/venues", handler.CreateVenue)
+			protected.PUT("/venues/:id", handler.UpdateVenue)
+			protected.DELETE("/venues/:id", handler.DeleteVenue)
+			protected.POST("/venues/:id/courts", handler.CreateCourt)
+			protected.DELETE("/courts/:id", handler.DeleteCourt)
+			protected.GET("/clubs/:id/templates", handler.ListMatchTemplates)
+			protected.POST("/clubs/:id/templates", handler.CreateMatchTemplate)
+			protected.PUT("/templates/:id", handler.UpdateMatchTemplate)
+			protected.DELETE("/templates/:id", handler.DeleteMatchTemplate)
+
+			// Recurring match series
+			protected.POST("/series", handler.CreateSeries)
+			protected.PUT("/series/:id", handler.UpdateSeries)
+			protected.DELETE("/series/:id", handler.EndSeries)
+			protected.POST("/series/:id/subscribe", handler.SubscribeSeries) // Auto-book every instance
+			protected.DELETE("/series/:id/subscribe", handler.UnsubscribeSeries)
+
+			protected.GET("/matches/:id/finance", handler.GetMatchFinance)
+			protected.GET("/matches/:id/refunds", handler.GetMatchRefunds) // ?status=pending
+			protected.PUT("/refunds/:id/settle", handler.SettleRefund)
+			protected.POST("/matches/:id/teams/generate", handler.GenerateTeams) // Adds a draft lineup
+			protected.GET("/matches/:id/lineups", handler.ListLineups)
+			protected.GET("/matches/:id/lineups/diff", handler.DiffLineups) // ?from=1&to=2
+			protected.GET("/matches/:id/lineups/:version", handler.GetLineup)
+			protected.POST("/matches/:id/lineups/:version/publish", handler.PublishLineup)
+			protected.POST("/matches/:id/lineups/:version/rollback", handler.RollbackLineup)
+			protected.GET("/matches/:id/ratings", handler.ListMatchRatings)
+			protected.PUT("/matches/:id/ratings/:userId", handler.SetPlayerRating)
+			protected.POST("/matches/:id/fixtures", handler.CreateFixtures)
+			protected.DELETE("/matches/:id/fixtures", handler.DeleteFixtures)
+			protected.PUT("/matches/:id/fixtures/:fixtureId/result", handler.RecordFixtureResult)
+			protected.GET("/matches/:id/team-constraints", handler.ListTeamConstraints)
+			protected.POST("/matches/:id/team-constraints", handler.CreateTeamConstraint) // lock, together or apart
+			protected.DELETE("/matches/:id/team-constraints/:constraintId", handler.DeleteTeamConstraint)
+			protected.PUT("/teams/members/:memberId", handler.UpdateTeamMember) // Manual move; lock to pin
 			protected.POST("/bookings", handler.JoinMatch)
-			protected.PUT("/bookings/:id/pay", handler.SetPaymentStatus)
+			protected.PUT("/bookings/:id/pay", handler.SetPaymentStatus)          // Manual override by organiser
+			protected.POST("/bookings/:id/payment", handler.CreateBookingPayment) // Online payment
+			protected.GET("/bookings/:id/payments", handler.GetBookingPayments)
 			protected.DELETE("/bookings/:id", handler.CancelBooking)
+			protected.POST("/bookings/:id/offer/accept", handler.AcceptOffer) // Waitlist offer
+			protected.POST("/bookings/:id/offer/decline", handler.DeclineOffer)
+			protected.GET("/bookings/:id/history", handler.GetBookingHistory)
 
 			protected.GET("/profile", handler.GetUser)
 			protected.PUT("/profile", handler.UpdateUser)
@@ -110,6 +195,7 @@ func main() {
 			protected.DELETE("/clubs/:id", handler.DeleteClub)
 			protected.POST("/clubs/:id/join", handler.JoinClub)
 			protected.POST("/clubs/:id/leave", handler.LeaveClub)
+			protected.GET("/clubs/:id/wallet", handler.GetClubWallet) // Refund credits
 
 			// Announcements
 			protected.POST("/clubs/:id/announcements", handler.CreateAnnouncement)
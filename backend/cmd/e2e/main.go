@@ -10,7 +10,7 @@ import (
 	"time"
 )
 
-// Config
+// Config; the server must run with DEV_MODE=true and PAYMENT_PROVIDER=fake
 const BaseURL = "http://localhost:8080/api"
 
 // Helper Types
@@ -47,6 +47,7 @@ var (
 	memberID    string
 	clubID      string
 	matchID     string
+	bookingID   string
 )
 
 func main() {
@@ -81,8 +82,16 @@ func main() {
 	fmt.Println("\n--- 4. Announcements ---")
 	createAnnouncement()
 
-	// 5. Cleanup
-	fmt.Println("\n--- 5. Cleanup ---")
+	// 5. Payments
+	fmt.Println("\n--- 5. Payments ---")
+	payBookingOnline()
+
+	// 6. Admin RBAC
+	fmt.Println("\n--- 6. Admin Access Control ---")
+	verifyAdminRoutesRejected()
+
+	// 7. Cleanup
+	fmt.Println("\n--- 7. Cleanup ---")
 	// cancelMatch()
 	// leaveClub()
 
@@ -230,9 +239,72 @@ func joinMatch() {
 	if resp.StatusCode != 200 && resp.StatusCode != 201 {
 		fatal("Join Match failed: " + string(body))
 	}
+
+	var booking struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(body, &booking)
+	bookingID = booking.ID
 	fmt.Println("✅ Member Joined Match")
 }
 
+// payBookingOnline pays the member's booking through the fake gateway and
+// checks that forged webhooks are refused and settlement is idempotent.
+func payBookingOnline() {
+	fmt.Println("Creating payment intent...")
+	resp, body := postJSON("/bookings/"+bookingID+"/payment", nil, memberToken)
+	if resp.StatusCode != 200 {
+		fatal("Create payment failed: " + string(body))
+	}
+	var intent struct {
+		ID          string  `json:"id"`
+		ProviderRef string  `json:"provider_ref"`
+		Amount      float64 `json:"amount"`
+		Status      string  `json:"status"`
+	}
+	json.Unmarshal(body, &intent)
+	if intent.ProviderRef == "" || intent.Status != "pending" {
+		fatal("Unexpected payment intent: " + string(body))
+	}
+
+	fmt.Println("Sending unsigned webhook...")
+	forged, _ := json.Marshal(map[string]interface{}{
+		"provider_ref": intent.ProviderRef,
+		"external_id":  intent.ID,
+		"status":       "paid",
+		"amount":       intent.Amount,
+	})
+	resp, body = request("POST", "/payments/webhook", forged, "")
+	if resp.StatusCode != http.StatusUnauthorized {
+		fatal(fmt.Sprintf("Forged webhook: expected 401, got %d: %s", resp.StatusCode, string(body)))
+	}
+
+	fmt.Println("Completing fake checkout (twice)...")
+	for i := 0; i < 2; i++ {
+		resp, body = postJSON("/payments/fake/"+intent.ProviderRef+"/complete", nil, "")
+		if resp.StatusCode != 200 {
+			fatal("Fake checkout failed: " + string(body))
+		}
+	}
+
+	resp, body = get("/bookings/"+bookingID+"/payments", memberToken)
+	if resp.StatusCode != 200 {
+		fatal("Get payments failed: " + string(body))
+	}
+	var history struct {
+		Booking struct {
+			IsPaid      bool    `json:"is_paid"`
+			AmountPaid  float64 `json:"amount_paid"`
+			Outstanding float64 `json:"outstanding"`
+		} `json:"booking"`
+	}
+	json.Unmarshal(body, &history)
+	if !history.Booking.IsPaid || history.Booking.Outstanding != 0 || history.Booking.AmountPaid != intent.Amount {
+		fatal("Booking not settled exactly once: " + string(body))
+	}
+	fmt.Println("✅ Booking Paid Online")
+}
+
 func createAnnouncement() {
 	fmt.Println("Creating Announcement...")
 	data := map[string]string{
@@ -246,6 +318,46 @@ func createAnnouncement() {
 	fmt.Println("✅ Announcement Created")
 }
 
+const zeroUUID = "00000000-0000-0000-0000-000000000000"
+
+// adminRoutes lists every route under /api/admin. A player token must never get through.
+var adminRoutes = []struct {
+	Method string
+	Path   string
+}{
+	{"GET", "/admin/users"},
+	{"GET", "/admin/users/" + zeroUUID},
+	{"POST", "/admin/users"},
+	{"PUT", "/admin/users/" + zeroUUID},
+	{"PUT", "/admin/users/" + zeroUUID + "/role"},
+	{"PUT", "/admin/users/" + zeroUUID + "/status"},
+	{"DELETE", "/admin/users/" + zeroUUID},
+	{"GET", "/admin/sports"},
+	{"POST", "/admin/sports"},
+	{"PUT", "/admin/sports/" + zeroUUID},
+	{"DELETE", "/admin/sports/" + zeroUUID},
+	{"PUT", "/admin/sports/" + zeroUUID + "/positions/order"},
+	{"POST", "/admin/positions"},
+	{"PUT", "/admin/positions/" + zeroUUID},
+	{"DELETE", "/admin/positions/" + zeroUUID},
+}
+
+func verifyAdminRoutesRejected() {
+	fmt.Println("Verifying player and anonymous access to admin routes is rejected...")
+	for _, route := range adminRoutes {
+		resp, body := request(route.Method, route.Path, nil, memberToken)
+		if resp.StatusCode != http.StatusForbidden {
+			fatal(fmt.Sprintf("%s %s with player token: expected 403, got %d: %s", route.Method, route.Path, resp.StatusCode, string(body)))
+		}
+
+		resp, body = request(route.Method, route.Path, nil, "")
+		if resp.StatusCode != http.StatusUnauthorized {
+			fatal(fmt.Sprintf("%s %s without token: expected 401, got %d: %s", route.Method, route.Path, resp.StatusCode, string(body)))
+		}
+	}
+	fmt.Println("✅ Admin routes reject non-admin users")
+}
+
 // --- HTTP Utils ---
 
 func post(path string, form map[string]string, token string) (*http.Response, []byte) {
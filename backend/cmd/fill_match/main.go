@@ -63,6 +63,7 @@ func main() {
 			MatchID:   match.ID,
 			UserID:    user.ID,
 			Position:  models.PositionPlayerFront, // default
+			Amount:    match.Price,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
@@ -71,6 +72,7 @@ func main() {
 		if currentCount < match.MaxPlayers {
 			booking.Status = models.StatusConfirmed
 			booking.IsPaid = true // Let's simplify and say they paid
+			booking.AmountPaid = booking.Amount
 		} else {
 			booking.Status = models.StatusWaitlist
 			waitlistCount++
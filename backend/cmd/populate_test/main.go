@@ -99,7 +99,7 @@ func main() {
 				MaxPlayers:  14,
 				Status:      "published",
 				// Default 2 GK, 12 Players
-				PositionQuotas: `{"gk": 2, "player_front": 12}`,
+				PositionQuotas: models.PositionQuotas{"gk": 2, "player_front": 12},
 				CreatedAt:      time.Now(),
 				UpdatedAt:      time.Now(),
 			}
@@ -192,13 +192,15 @@ func main() {
 		}
 
 		booking := models.Booking{
-			MatchID:   match.ID,
-			UserID:    user.ID,
-			Position:  pos,
-			Status:    models.StatusConfirmed,
-			IsPaid:    true,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+			MatchID:    match.ID,
+			UserID:     user.ID,
+			Position:   pos,
+			Status:     models.StatusConfirmed,
+			IsPaid:     true,
+			Amount:     match.Price,
+			AmountPaid: match.Price,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
 		}
 
 		if err := db.Create(&booking).Error; err != nil {
@@ -6,6 +6,7 @@ import (
 	"math/rand"
 	"reserve_game/internal/config"
 	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -23,6 +24,9 @@ func main() {
 	}
 
 	// Auto Migrate for Seeder
+	if err := repository.MigratePositionColumns(db); err != nil {
+		log.Fatal("Failed to migrate position columns:", err)
+	}
 	db.AutoMigrate(&models.User{}, &models.Match{}, &models.Booking{}, &models.Team{}, &models.TeamMember{}, &models.Sport{}, &models.SportPosition{})
 
 	log.Println("Database connected. Starting seeder...")
@@ -159,13 +163,13 @@ func main() {
 			gt := gameTypes[rand.Intn(len(gameTypes))]
 
 			// Generate Quotas based on GameType
-			quotas := `{"player_front": 10}`
+			quotas := models.PositionQuotas{"player_front": 10}
 			if gt == "minisoccer" {
-				quotas = `{"gk": 2, "player_front": 14}`
+				quotas = models.PositionQuotas{"gk": 2, "player_front": 14}
 			} else if gt == "futsal" {
-				quotas = `{"gk": 2, "player_front": 10}`
+				quotas = models.PositionQuotas{"gk": 2, "player_front": 10}
 			} else if gt == "football" {
-				quotas = `{"gk": 2, "defender": 8, "midfielder": 8, "forward": 4}`
+				quotas = models.PositionQuotas{"gk": 2, "defender": 8, "midfielder": 8, "forward": 4}
 			}
 
 			match := models.Match{
@@ -197,13 +201,13 @@ func main() {
 		gt := gameTypes[rand.Intn(len(gameTypes))]
 
 		// Generate Quotas based on GameType
-		quotas := `{"player_front": 10}`
+		quotas := models.PositionQuotas{"player_front": 10}
 		if gt == "minisoccer" {
-			quotas = `{"gk": 2, "player_front": 14}`
+			quotas = models.PositionQuotas{"gk": 2, "player_front": 14}
 		} else if gt == "futsal" {
-			quotas = `{"gk": 2, "player_front": 10}`
+			quotas = models.PositionQuotas{"gk": 2, "player_front": 10}
 		} else if gt == "football" {
-			quotas = `{"gk": 2, "defender": 8, "midfielder": 8, "forward": 4}`
+			quotas = models.PositionQuotas{"gk": 2, "defender": 8, "midfielder": 8, "forward": 4}
 		}
 
 		match := models.Match{
@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"os"
 	"sync"
+	"time"
 )
 
 const (
 	baseURL = "http://localhost:8080/api"
 )
 
+var failed bool
+
 func main() {
 	// 1. Login as Admin/Host to Create Match
 	token := login("tester@example.com", "password123")
@@ -20,46 +24,85 @@ func main() {
 		panic("Login failed")
 	}
 
-	// 2. Create a Club (if needed or use existing)
-	clubID := "96363d7a-f918-4723-a990-128e28d77c82" // Hardcoded from previous checks
-
-	// 3. Create a Match with Quota = 5
-	matchID := createMatch(token, clubID)
-	fmt.Printf("Created Match: %s with quota 5\n", matchID)
+	// 2. Create a Club owned by the tester (only club owners can schedule matches)
+	clubID := createClub(token)
 
-	// 4. Register 10 dummy users
-	users := make([]string, 10)
+	// 3. Register 10 dummy users
 	userTokens := make([]string, 10)
 	for i := 0; i < 10; i++ {
 		email := fmt.Sprintf("stress%d@test.com", i)
 		register(email)
 		userTokens[i] = login(email, "password123")
-		users[i] = email
 	}
 
-	// 5. Concurrent Join
-	// We have 5 slots. We try to join with 10 users at once.
-	// Expected: 5 confirmed, 5 waitlist (or 5 confirmed, 5 rejected if logic differs).
-	// But mostly we check if we oversell CONFIRMED.
+	// 4. Per-position quota: 5 field player slots, plenty of room overall.
+	// The organiser is auto-joined into one of those 5 slots.
+	fmt.Println("\n--- Scenario 1: position quota ---")
+	matchID := createMatch(token, clubID, 20, `{"player_front": 5}`)
+	race(userTokens, matchID, func(int) string { return "player_front" })
+	checkMatch(token, matchID, 20, map[string]int{"player_front": 5})
+
+	// 5. Global cap: positions would allow 15, but MaxPlayers is 5.
+	fmt.Println("\n--- Scenario 2: MaxPlayers cap across positions ---")
+	matchID = createMatch(token, clubID, 5, `{"gk": 5, "player_front": 10}`)
+	bookingIDs := race(userTokens, matchID, func(i int) string {
+		if i%2 == 0 {
+			return "gk"
+		}
+		return "player_front"
+	})
+	checkMatch(token, matchID, 5, map[string]int{"gk": 5, "player_front": 10})
+
+	// 6. Concurrent cancellations: waitlist offers must respect both limits too.
+	fmt.Println("\n--- Scenario 3: concurrent cancel + waitlist offers ---")
 	var wg sync.WaitGroup
 	start := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		if bookingIDs[i] == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int) {
+			defer wg.Done()
+			<-start
+			cancelBooking(userTokens[idx], bookingIDs[idx])
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	checkMatch(token, matchID, 5, map[string]int{"gk": 5, "player_front": 10})
 
-	for i := 0; i < 10; i++ {
+	if failed {
+		fmt.Println("\nSTRESS TEST FAILED")
+		os.Exit(1)
+	}
+	fmt.Println("\nALL STRESS SCENARIOS PASSED")
+}
+
+// race joins every user at once and returns their booking IDs (index-aligned).
+func race(userTokens []string, matchID string, positionFor func(int) string) []string {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	start := make(chan struct{})
+	bookingIDs := make([]string, len(userTokens))
+
+	for i := range userTokens {
 		wg.Add(1)
 		go func(idx int) {
 			defer wg.Done()
 			<-start // Wait for signal
-			joinMatch(userTokens[idx], matchID)
+			id := joinMatch(userTokens[idx], matchID, positionFor(idx))
+			mu.Lock()
+			bookingIDs[idx] = id
+			mu.Unlock()
 		}(i)
 	}
 
 	fmt.Println("Starting Race...")
 	close(start) // GO!
 	wg.Wait()
-	fmt.Println("Race Finished.")
-
-	// 6. Check Result
-	checkMatch(token, matchID)
+	fmt.Println("\nRace Finished.")
+	return bookingIDs
 }
 
 func login(email, password string) string {
@@ -94,49 +137,78 @@ func register(email string) {
 	http.Post(baseURL+"/register", "application/json", bytes.NewBuffer(body))
 }
 
-func createMatch(token, clubID string) string {
-	payload := map[string]interface{}{
+func createClub(token string) string {
+	res := doJSON("POST", "/clubs", token, map[string]interface{}{
+		"name":        "Stress Club " + time.Now().Format("150405"),
+		"description": "Stress Test",
+	})
+	if id, ok := res["id"].(string); ok {
+		return id
+	}
+	fmt.Println("Create Club Failed:", res)
+	panic("Failed to create club")
+}
+
+func createMatch(token, clubID string, maxPlayers int, quotas string) string {
+	res := doJSON("POST", "/matches", token, map[string]interface{}{
 		"club_id":         clubID,
 		"title":           "Stress Match",
 		"description":     "Stress Test",
 		"game_type":       "Mini Soccer",
 		"location":        "Test Loc",
 		"price":           10000,
-		"max_players":     5,
-		"date":            "2026-05-01",
+		"max_players":     maxPlayers,
+		"date":            time.Now().Add(48 * time.Hour).Format("2006-01-02"),
 		"time":            "10:00",
-		"position_quotas": `{"player_front": 5}`,
+		"position_quotas": quotas,
+	})
+	if id, ok := res["id"].(string); ok {
+		fmt.Printf("Created Match: %s (max_players=%d, quotas=%s)\n", id, maxPlayers, quotas)
+		return id
+	}
+	// Fallback or panic if failed
+	fmt.Println("Create Match Failed:", res)
+	panic("Failed to create match")
+}
+
+func joinMatch(token, matchID, position string) string {
+	payload := map[string]string{
+		"match_id": matchID,
+		"position": position,
 	}
 	body, _ := json.Marshal(payload)
-	req, _ := http.NewRequest("POST", baseURL+"/matches", bytes.NewBuffer(body))
+	req, _ := http.NewRequest("POST", baseURL+"/bookings", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	client := &http.Client{}
 	resp, err := client.Do(req)
 	if err != nil {
-		panic(err)
+		fmt.Println("Error:", err)
+		return ""
 	}
 	defer resp.Body.Close()
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	if resp.StatusCode != 200 {
+		fmt.Printf("X (%d: %s) ", resp.StatusCode, string(respBody))
+		return ""
+	}
 
-	var res map[string]interface{}
-	json.NewDecoder(resp.Body).Decode(&res)
-	if id, ok := res["ID"].(string); ok {
-		return id
+	var booking struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
 	}
-	// Fallback or panic if failed
-	fmt.Println("Create Match Failed:", res)
-	panic("Failed to create match")
+	json.Unmarshal(respBody, &booking)
+	if booking.Status == "waitlist" {
+		fmt.Print("w")
+	} else {
+		fmt.Print(".")
+	}
+	return booking.ID
 }
 
-func joinMatch(token, matchID string) {
-	payload := map[string]string{
-		"match_id": matchID,
-		"position": "player_front",
-	}
-	body, _ := json.Marshal(payload)
-	req, _ := http.NewRequest("POST", baseURL+"/bookings", bytes.NewBuffer(body))
-	req.Header.Set("Content-Type", "application/json")
+func cancelBooking(token, bookingID string) {
+	req, _ := http.NewRequest("DELETE", baseURL+"/bookings/"+bookingID, nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	client := &http.Client{}
@@ -149,40 +221,94 @@ func joinMatch(token, matchID string) {
 	if resp.StatusCode != 200 {
 		body, _ := ioutil.ReadAll(resp.Body)
 		fmt.Printf("X (%d: %s) ", resp.StatusCode, string(body))
-	} else {
-		fmt.Print(".")
 	}
-	// We don't care about individual error here, we check final count.
 }
 
-func checkMatch(token, matchID string) {
+func checkMatch(token, matchID string, maxPlayers int, quotas map[string]int) {
 	req, _ := http.NewRequest("GET", baseURL+"/matches/"+matchID, nil)
 	req.Header.Set("Authorization", "Bearer "+token)
 
 	client := &http.Client{}
-	resp, _ := client.Do(req)
+	resp, err := client.Do(req)
+	if err != nil {
+		panic(err)
+	}
 	defer resp.Body.Close()
 
 	bodyBytes, _ := ioutil.ReadAll(resp.Body)
-	// fmt.Println(string(bodyBytes))
 
 	var match struct {
 		Bookings []struct {
-			Status string
-		}
+			Position string `json:"position"`
+			Status   string `json:"status"`
+		} `json:"bookings"`
 	}
 	json.Unmarshal(bodyBytes, &match)
 
+	// Confirmed players and open waitlist offers both hold a spot
 	confirmedCount := 0
+	offeredCount := 0
+	waitlistCount := 0
+	perPosition := map[string]int{}
+	waitlistPerPosition := map[string]int{}
 	for _, b := range match.Bookings {
-		if b.Status == "confirmed" {
+		switch b.Status {
+		case "confirmed":
 			confirmedCount++
+			perPosition[b.Position]++
+		case "offered":
+			offeredCount++
+			perPosition[b.Position]++
+		case "waitlist":
+			waitlistCount++
+			waitlistPerPosition[b.Position]++
+		}
+	}
+	held := confirmedCount + offeredCount
+	fmt.Printf("RESULT: Confirmed = %d, Offered = %d (Expected Max %d together), Waitlist = %d, Held per position = %v\n", confirmedCount, offeredCount, maxPlayers, waitlistCount, perPosition)
+
+	ok := held <= maxPlayers
+	for pos, count := range perPosition {
+		if count > quotas[pos] {
+			ok = false
+		}
+	}
+	// Nobody should sit on the waitlist while a spot is free and not on offer
+	if held < maxPlayers {
+		stuck := false
+		for pos, waiting := range waitlistPerPosition {
+			if waiting > 0 && perPosition[pos] < quotas[pos] {
+				stuck = true
+			}
+		}
+		if stuck {
+			fmt.Println("Waitlisted players were not offered free spots")
+			ok = false
 		}
 	}
-	fmt.Printf("\nFINAL RESULT: Confirmed Bookings = %d (Expected Max 5)\n", confirmedCount)
-	if confirmedCount > 5 {
-		fmt.Println("TEST FAILED: RACE CONDITION DETECTED!")
+
+	if !ok {
+		fmt.Println("TEST FAILED: OVERSELLING OR MISSED OFFER DETECTED!")
+		failed = true
 	} else {
 		fmt.Println("TEST PASSED: No Overselling.")
 	}
 }
+
+func doJSON(method, path, token string, payload interface{}) map[string]interface{} {
+	body, _ := json.Marshal(payload)
+	req, _ := http.NewRequest(method, baseURL+path, bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	var res map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&res)
+	return res
+}
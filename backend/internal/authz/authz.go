@@ -0,0 +1,32 @@
+package authz
+
+import "reserve_game/internal/models"
+
+// IsPlatformAdmin reports whether the user holds the platform-wide admin role.
+func IsPlatformAdmin(user *models.User) bool {
+	return user != nil && user.Role == models.RoleAdmin
+}
+
+// CanManageClub - club owner or platform admin
+func CanManageClub(user *models.User, club *models.Club) bool {
+	if user == nil || club == nil {
+		return false
+	}
+	return IsPlatformAdmin(user) || club.CreatorID == user.ID
+}
+
+// CanManageMatch - match creator or platform admin
+func CanManageMatch(user *models.User, match *models.Match) bool {
+	if user == nil || match == nil {
+		return false
+	}
+	return IsPlatformAdmin(user) || match.CreatorID == user.ID
+}
+
+// CanManageBooking - the booking's own player or anyone who manages the match
+func CanManageBooking(user *models.User, booking *models.Booking, match *models.Match) bool {
+	if user == nil || booking == nil {
+		return false
+	}
+	return booking.UserID == user.ID || CanManageMatch(user, match)
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"log"
 	"os"
 
@@ -10,6 +11,12 @@ import (
 type Config struct {
 	DatabaseURL string
 	Port        string
+	DevMode     bool // DEV_MODE=true; allows the fake payment provider and its checkout route
+
+	// Online payments, both required
+	PaymentProvider      string // "fake" for local development
+	PaymentWebhookSecret string
+	PublicBaseURL        string // Used to build provider redirect / checkout links
 }
 
 func LoadConfig() *Config {
@@ -21,5 +28,32 @@ func LoadConfig() *Config {
 	return &Config{
 		DatabaseURL: os.Getenv("DATABASE_URL"),
 		Port:        os.Getenv("PORT"), // Default to 8080 or process env
+		DevMode:     os.Getenv("DEV_MODE") == "true",
+
+		PaymentProvider:      os.Getenv("PAYMENT_PROVIDER"),
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		PublicBaseURL:        getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+	}
+}
+
+// Validate refuses settings that would let payments fail open: a missing
+// provider or webhook secret, or the fake provider outside dev mode.
+func (c *Config) Validate() error {
+	if c.PaymentProvider == "" {
+		return errors.New("PAYMENT_PROVIDER is required")
+	}
+	if c.PaymentWebhookSecret == "" {
+		return errors.New("PAYMENT_WEBHOOK_SECRET is required")
+	}
+	if c.PaymentProvider == "fake" && !c.DevMode {
+		return errors.New("the fake payment provider needs DEV_MODE=true")
+	}
+	return nil
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
 	}
+	return fallback
 }
@@ -0,0 +1,573 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RegisterAdminRoutes mounts the platform admin endpoints under /admin on
+// api. Every route requires a logged-in user with the admin role.
+func (h *Handler) RegisterAdminRoutes(api gin.IRouter) {
+	admin := api.Group("/admin")
+	admin.Use(middleware.AuthMiddleware(), middleware.LoadUser(h.Repo), middleware.RequireRole(models.RoleAdmin))
+	{
+		admin.GET("/users", h.AdminGetAllUsers)
+		admin.GET("/users/:id", h.AdminGetUser)
+		admin.POST("/users", h.AdminCreateUser)
+		admin.PUT("/users/:id", h.AdminUpdateUser)
+		admin.PUT("/users/:id/role", h.AdminUpdateUserRole)
+		admin.PUT("/users/:id/status", h.AdminUpdateUserStatus) // Ban / suspend / reactivate
+		admin.DELETE("/users/:id", h.AdminDeleteUser)
+
+		// Sport & position master data
+		admin.GET("/sports", h.GetMasterSports)
+		admin.POST("/sports", h.AdminCreateSport)
+		admin.PUT("/sports/:id", h.AdminUpdateSport)
+		admin.DELETE("/sports/:id", h.AdminDeleteSport)
+		admin.PUT("/sports/:id/positions/order", h.AdminReorderPositions)
+		admin.POST("/positions", h.AdminCreatePosition)
+		admin.PUT("/positions/:id", h.AdminUpdatePosition)
+		admin.DELETE("/positions/:id", h.AdminDeletePosition)
+	}
+}
+
+// blockedMessage explains to a banned or suspended user why they cannot log in
+func blockedMessage(user *models.User) string {
+	msg := "Account is " + string(user.Status)
+	if user.Status == models.UserStatusSuspended && user.SuspendedUntil != nil {
+		msg += " until " + user.SuspendedUntil.Format(time.RFC3339)
+	}
+	if user.StatusReason != "" {
+		msg += ": " + user.StatusReason
+	}
+	return msg
+}
+
+// AdminGetAllUsers - search users with cursor pagination, role and status
+// filter. The total is also sent in the X-Total-Count header.
+func (h *Handler) AdminGetAllUsers(c *gin.Context) {
+	page, err := h.Repo.ListUsers(repository.UserFilter{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+	}, parsePageQuery(c))
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+
+	c.Header("X-Total-Count", strconv.FormatInt(page.Total, 10))
+	c.JSON(http.StatusOK, models.Page[models.SelfUser]{
+		Items:      models.SelfUsers(page.Items),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	})
+}
+
+// AdminGetUser
+func (h *Handler) AdminGetUser(c *gin.Context) {
+	user, err := h.Repo.GetUserByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, user.Self())
+}
+
+// AdminCreateUser - create a local account on behalf of a user
+func (h *Handler) AdminCreateUser(c *gin.Context) {
+	var req models.AdminCreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if _, err := h.Repo.GetUserByEmail(req.Email); err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Email already registered"})
+		return
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RolePlayer
+	}
+
+	user := &models.User{
+		Name:      req.Name,
+		Email:     req.Email,
+		Phone:     req.Phone,
+		Password:  string(hashedPassword),
+		Provider:  "local",
+		Role:      role,
+		Status:    models.UserStatusActive,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	if err := h.Repo.CreateUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user.Self())
+}
+
+// AdminUpdateUser - edit profile fields of any user
+func (h *Handler) AdminUpdateUser(c *gin.Context) {
+	var req models.AdminUpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.Repo.GetUserByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if req.Email != "" && req.Email != user.Email {
+		if _, err := h.Repo.GetUserByEmail(req.Email); err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Email already registered"})
+			return
+		}
+		user.Email = req.Email
+	}
+	if req.Name != "" {
+		user.Name = req.Name
+	}
+	if req.Phone != "" {
+		user.Phone = req.Phone
+	}
+	if req.Avatar != "" {
+		user.Avatar = req.Avatar
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := h.Repo.UpdateUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.Self())
+}
+
+// AdminUpdateUserRole - promote to / demote from platform admin
+func (h *Handler) AdminUpdateUserRole(c *gin.Context) {
+	var req models.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin, _ := middleware.CurrentUser(c)
+	id := c.Param("id")
+	if admin != nil && admin.ID == id && req.Role != models.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove your own admin role"})
+		return
+	}
+
+	user, err := h.Repo.GetUserByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	user.Role = req.Role
+	user.UpdatedAt = time.Now()
+
+	if err := h.Repo.UpdateUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.Self())
+}
+
+// AdminUpdateUserStatus - ban, suspend or reactivate an account
+func (h *Handler) AdminUpdateUserStatus(c *gin.Context) {
+	var req models.UpdateUserStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin, _ := middleware.CurrentUser(c)
+	id := c.Param("id")
+	if admin != nil && admin.ID == id && req.Status != models.UserStatusActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend or ban yourself"})
+		return
+	}
+
+	if req.Status == models.UserStatusSuspended && req.SuspendedUntil != nil && !req.SuspendedUntil.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "suspended_until must be in the future"})
+		return
+	}
+
+	user, err := h.Repo.GetUserByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	user.Status = req.Status
+	user.StatusReason = req.Reason
+	user.SuspendedUntil = nil
+	if req.Status == models.UserStatusSuspended {
+		user.SuspendedUntil = req.SuspendedUntil
+	}
+	if req.Status == models.UserStatusActive {
+		user.StatusReason = ""
+	}
+	user.UpdatedAt = time.Now()
+
+	if err := h.Repo.UpdateUser(user); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user.Self())
+}
+
+// AdminDeleteUser
+func (h *Handler) AdminDeleteUser(c *gin.Context) {
+	admin, _ := middleware.CurrentUser(c)
+	id := c.Param("id")
+	if admin != nil && admin.ID == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot delete your own account here"})
+		return
+	}
+
+	if _, err := h.Repo.GetUserByID(id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := h.Repo.DeleteUser(id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user. Consider banning the account instead."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User deleted"})
+}
+
+// AdminCreateSport
+func (h *Handler) AdminCreateSport(c *gin.Context) {
+	var req models.SportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if _, err := h.Repo.GetSportByCode(code); err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sport code already exists"})
+		return
+	}
+
+	sport := &models.Sport{
+		Name: strings.TrimSpace(req.Name),
+		Code: code,
+	}
+	if err := h.Repo.CreateSport(sport); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, sport)
+}
+
+// AdminUpdateSport - renaming also rewrites game_type on existing matches,
+// templates and series, and moves player ratings to the new code, so they
+// stay linked
+func (h *Handler) AdminUpdateSport(c *gin.Context) {
+	var req models.SportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sport, err := h.Repo.GetSportByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sport not found"})
+		return
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	name := strings.TrimSpace(req.Name)
+	if code != sport.Code {
+		if _, err := h.Repo.GetSportByCode(code); err == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Sport code already exists"})
+			return
+		}
+	}
+
+	err = h.Repo.RunTransaction(func(repo repository.Repository) error {
+		if code != sport.Code {
+			if err := repo.RenameGameType(sport.Code, code); err != nil {
+				return err
+			}
+			if err := repo.RenameRatingSport(sport.Code, code); err != nil {
+				return err
+			}
+		}
+		if name != sport.Name {
+			if err := repo.RenameGameType(sport.Name, name); err != nil {
+				return err
+			}
+		}
+		sport.Code = code
+		sport.Name = name
+		return repo.UpdateSport(sport)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, sport)
+}
+
+// AdminDeleteSport - refuses while any match, template or series still uses the sport
+func (h *Handler) AdminDeleteSport(c *gin.Context) {
+	sport, err := h.Repo.GetSportByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sport not found"})
+		return
+	}
+
+	usage, err := h.Repo.CountSportUsage(sport.Code, sport.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if usage.InUse() {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Sport is used by %s and cannot be deleted", describeUsage(usage))})
+		return
+	}
+
+	if err := h.Repo.DeleteSport(sport.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sport deleted"})
+}
+
+// AdminCreatePosition
+func (h *Handler) AdminCreatePosition(c *gin.Context) {
+	var req models.SportPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sport, err := h.Repo.GetSportByID(req.SportID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Sport not found"})
+		return
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	sortOrder := 0
+	for _, p := range sport.Positions {
+		if p.Code == code {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Position code already exists for this sport"})
+			return
+		}
+		if p.SortOrder >= sortOrder {
+			sortOrder = p.SortOrder + 1
+		}
+	}
+	if req.SortOrder != nil {
+		sortOrder = *req.SortOrder
+	}
+
+	position := &models.SportPosition{
+		SportID:      sport.ID,
+		Code:         code,
+		Name:         strings.TrimSpace(req.Name),
+		DefaultQuota: req.DefaultQuota,
+		SortOrder:    sortOrder,
+	}
+	if err := h.Repo.CreateSportPosition(position); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, position)
+}
+
+// AdminUpdatePosition
+func (h *Handler) AdminUpdatePosition(c *gin.Context) {
+	var req models.SportPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	position, err := h.Repo.GetSportPositionByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return
+	}
+	if req.SportID != position.SportID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Position cannot be moved to another sport"})
+		return
+	}
+
+	sport, err := h.Repo.GetSportByID(position.SportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sport not found"})
+		return
+	}
+
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	for _, p := range sport.Positions {
+		if p.ID != position.ID && p.Code == code {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Position code already exists for this sport"})
+			return
+		}
+	}
+
+	if code != position.Code && !h.positionUnused(c, sport, position.Code, "renamed") {
+		return
+	}
+
+	position.Code = code
+	position.Name = strings.TrimSpace(req.Name)
+	position.DefaultQuota = req.DefaultQuota
+	if req.SortOrder != nil {
+		position.SortOrder = *req.SortOrder
+	}
+
+	if err := h.Repo.UpdateSportPosition(position); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, position)
+}
+
+// AdminDeletePosition
+func (h *Handler) AdminDeletePosition(c *gin.Context) {
+	position, err := h.Repo.GetSportPositionByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Position not found"})
+		return
+	}
+	sport, err := h.Repo.GetSportByID(position.SportID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sport not found"})
+		return
+	}
+	if !h.positionUnused(c, sport, position.Code, "deleted") {
+		return
+	}
+
+	if err := h.Repo.DeleteSportPosition(c.Param("id")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Position deleted"})
+}
+
+// positionUnused answers 409 when matches, templates, series or bookings of
+// the sport still refer to the position code, which would leave them
+// pointing at nothing.
+func (h *Handler) positionUnused(c *gin.Context, sport *models.Sport, code string, action string) bool {
+	usage, err := h.Repo.CountPositionUsage(code, sport.Code, sport.Name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if usage.InUse() {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Position is used by %s and cannot be %s", describeUsage(usage), action)})
+		return false
+	}
+	return true
+}
+
+// describeUsage lists the non-zero counts, e.g. "2 match(es) and 1 template(s)".
+func describeUsage(usage repository.SportUsage) string {
+	var parts []string
+	for _, part := range []struct {
+		count int64
+		noun  string
+	}{
+		{usage.Matches, "match(es)"},
+		{usage.Templates, "template(s)"},
+		{usage.Series, "series"},
+		{usage.Bookings, "booking(s)"},
+	} {
+		if part.count > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", part.count, part.noun))
+		}
+	}
+	if len(parts) < 2 {
+		return strings.Join(parts, "")
+	}
+	return strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+}
+
+// AdminReorderPositions - sets sort_order from the order of position_ids
+func (h *Handler) AdminReorderPositions(c *gin.Context) {
+	var req models.ReorderPositionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sport, err := h.Repo.GetSportByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Sport not found"})
+		return
+	}
+
+	byID := make(map[string]*models.SportPosition, len(sport.Positions))
+	for i := range sport.Positions {
+		byID[sport.Positions[i].ID] = &sport.Positions[i]
+	}
+	if len(req.PositionIDs) != len(byID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position_ids must list every position of the sport exactly once"})
+		return
+	}
+
+	err = h.Repo.RunTransaction(func(repo repository.Repository) error {
+		for i, id := range req.PositionIDs {
+			position, ok := byID[id]
+			if !ok {
+				return fmt.Errorf("position %s does not belong to this sport", id)
+			}
+			delete(byID, id) // Guards against duplicates
+			position.SortOrder = i
+			if err := repo.UpdateSportPosition(position); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sport, _ = h.Repo.GetSportByID(sport.ID)
+	c.JSON(http.StatusOK, sport)
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// adminRepo only knows its users; every other method panics through the
+// embedded nil interface, so a request that gets past the role check fails
+// the test.
+type adminRepo struct {
+	repository.Repository
+	users map[string]models.User
+}
+
+func (r *adminRepo) GetUserByID(id string) (*models.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return &u, nil
+}
+
+func testToken(t *testing.T, userID string) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"user_id": userID,
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}).SignedString(middleware.SecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// TestAdminRoutesRejectNonAdmins calls every route RegisterAdminRoutes mounts
+// as a player and without a token.
+func TestAdminRoutesRejectNonAdmins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{Repo: &adminRepo{users: map[string]models.User{
+		"player": {ID: "player", Name: "Player", Role: models.RolePlayer},
+	}}}
+
+	r := gin.New()
+	h.RegisterAdminRoutes(r.Group("/api"))
+
+	routes := 0
+	playerToken := testToken(t, "player")
+	for _, route := range r.Routes() {
+		if !strings.HasPrefix(route.Path, "/api/admin/") {
+			continue
+		}
+		routes++
+		path := strings.NewReplacer(":id", "00000000-0000-0000-0000-000000000000").Replace(route.Path)
+		t.Run(route.Method+" "+route.Path, func(t *testing.T) {
+			for _, tc := range []struct {
+				name  string
+				token string
+				want  int
+			}{
+				{"player", playerToken, http.StatusForbidden},
+				{"anonymous", "", http.StatusUnauthorized},
+			} {
+				req := httptest.NewRequest(route.Method, path, strings.NewReader("{}"))
+				req.Header.Set("Content-Type", "application/json")
+				if tc.token != "" {
+					req.Header.Set("Authorization", "Bearer "+tc.token)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				if w.Code != tc.want {
+					t.Errorf("%s: status = %d, want %d, body %s", tc.name, w.Code, tc.want, w.Body.String())
+				}
+			}
+		})
+	}
+	if routes == 0 {
+		t.Fatal("no admin routes registered")
+	}
+}
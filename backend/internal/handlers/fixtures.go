@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMatchResults - the games played inside the match and the live
+// standings. Public, like the published teams.
+func (h *Handler) GetMatchResults(c *gin.Context) {
+	results, err := h.FixtureService.GetResults(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// CreateFixtures - schedules round robin, winner stays on or knockout games
+// between the teams of the published lineup. Organisers only.
+func (h *Handler) CreateFixtures(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	var req models.CreateFixturesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	results, err := h.FixtureService.CreateFixtures(match.ID, req, user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, results)
+}
+
+// RecordFixtureResult - enters or corrects the score of one game.
+func (h *Handler) RecordFixtureResult(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+
+	var req models.FixtureResultRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	results, err := h.FixtureService.RecordResult(match.ID, c.Param("fixtureId"), *req.HomeScore, *req.AwayScore)
+	if err != nil {
+		fixtureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, results)
+}
+
+// DeleteFixtures - drops the schedule and every result entered for it.
+func (h *Handler) DeleteFixtures(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	if err := h.FixtureService.DeleteFixtures(match.ID); err != nil {
+		fixtureError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Fixtures deleted"})
+}
+
+func fixtureError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrNoFixtures) || errors.Is(err, service.ErrFixtureNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
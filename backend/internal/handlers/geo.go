@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"reserve_game/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRadiusKm = 10.0
+	maxRadiusKm     = 200.0
+)
+
+// parseGeoQuery reads ?lat=&lng=&radius_km= for nearby searches. Returns nil
+// when no location was given.
+func parseGeoQuery(c *gin.Context) (*repository.GeoFilter, error) {
+	latStr, lngStr := c.Query("lat"), c.Query("lng")
+	if latStr == "" && lngStr == "" {
+		return nil, nil
+	}
+	lat, err1 := strconv.ParseFloat(latStr, 64)
+	lng, err2 := strconv.ParseFloat(lngStr, 64)
+	if err1 != nil || err2 != nil {
+		return nil, errors.New("lat and lng must both be numbers")
+	}
+	if err := validateCoordinates(&lat, &lng); err != nil {
+		return nil, err
+	}
+
+	radius := defaultRadiusKm
+	if r := c.Query("radius_km"); r != "" {
+		radius, err1 = strconv.ParseFloat(r, 64)
+		if err1 != nil || radius <= 0 {
+			return nil, errors.New("radius_km must be a positive number")
+		}
+		if radius > maxRadiusKm {
+			radius = maxRadiusKm
+		}
+	}
+	return &repository.GeoFilter{Lat: lat, Lng: lng, RadiusKm: radius}, nil
+}
+
+// validateCoordinates accepts both or neither of lat/lng, within range.
+func validateCoordinates(lat, lng *float64) error {
+	if lat == nil && lng == nil {
+		return nil
+	}
+	if lat == nil || lng == nil {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if *lat < -90 || *lat > 90 || *lng < -180 || *lng > 180 {
+		return errors.New("latitude must be within ±90 and longitude within ±180")
+	}
+	return nil
+}
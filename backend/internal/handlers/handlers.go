@@ -2,14 +2,16 @@ package handlers
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
+	"reserve_game/internal/authz"
 	"reserve_game/internal/middleware"
 	"reserve_game/internal/models"
+	"reserve_game/internal/payment"
 	"reserve_game/internal/repository"
 	"reserve_game/internal/service"
-	"strconv"
 	"strings"
 	"time"
 
@@ -21,13 +23,22 @@ import (
 type Handler struct {
 	BookingService *service.BookingService
 	TeamService    *service.TeamService
+	PaymentService *service.PaymentService
+	RefundService  *service.RefundService
+	SeriesService  *service.SeriesService
+	FixtureService *service.FixtureService
 	Repo           repository.Repository
 }
 
-func NewHandler(repo repository.Repository) *Handler {
+func NewHandler(repo repository.Repository, provider payment.Provider) *Handler {
+	bookings := service.NewBookingService(repo)
 	return &Handler{
-		BookingService: service.NewBookingService(repo),
+		BookingService: bookings,
 		TeamService:    service.NewTeamService(repo),
+		PaymentService: service.NewPaymentService(repo, provider),
+		RefundService:  service.NewRefundService(repo, provider),
+		SeriesService:  service.NewSeriesService(repo, bookings),
+		FixtureService: service.NewFixtureService(repo),
 		Repo:           repo,
 	}
 }
@@ -127,6 +138,11 @@ func (h *Handler) Login(c *gin.Context) {
 		}
 	}
 
+	if user.IsBlocked(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": blockedMessage(user)})
+		return
+	}
+
 	// Generate REAL JWT using the real User ID
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID,
@@ -139,9 +155,9 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"token": tokenString,
-		"user":  user,
+	c.JSON(http.StatusOK, models.AuthResponse{
+		Token: tokenString,
+		User:  user.Self(),
 	})
 }
 
@@ -159,7 +175,7 @@ func (h *Handler) GetUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, user.Self())
 }
 
 // UpdateUser
@@ -201,7 +217,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, user)
+	c.JSON(http.StatusOK, user.Self())
 }
 
 // DeleteUser
@@ -224,15 +240,13 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 // SetPaymentStatus
 func (h *Handler) SetPaymentStatus(c *gin.Context) {
 	bookingID := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	var req struct {
-		IsPaid bool `json:"is_paid"`
-	}
+	var req models.SetPaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -251,23 +265,51 @@ func (h *Handler) SetPaymentStatus(c *gin.Context) {
 		return
 	}
 
-	if match.CreatorID != userID.(string) {
+	if !authz.CanManageMatch(user, match) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only match owner can update payment status"})
 		return
 	}
 
-	if err := h.BookingService.SetPaidStatus(bookingID, req.IsPaid); err != nil {
+	// Manual override (cash / transfer); logged with the acting organiser
+	if err := h.BookingService.SetPaidStatus(bookingID, user.ID, req.IsPaid, req.AmountPaid, req.Note); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Payment status updated"})
 }
 
+// GetMatchFinance - expected, collected and outstanding totals for the organiser
+func (h *Handler) GetMatchFinance(c *gin.Context) {
+	matchID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	match, err := h.Repo.GetMatchByID(matchID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if !authz.CanManageMatch(user, match) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only match owner can view finances"})
+		return
+	}
+
+	summary, err := h.BookingService.FinancialSummary(matchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+
 // GenerateTeams
 func (h *Handler) GenerateTeams(c *gin.Context) {
 	matchID := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -278,20 +320,28 @@ func (h *Handler) GenerateTeams(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
 		return
 	}
-	if match.CreatorID != userID.(string) {
+	if !authz.CanManageMatch(user, match) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only match owner can generate teams"})
 		return
 	}
 
-	teams, err := h.TeamService.GenerateTeams(matchID)
+	var req models.GenerateTeamsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	split, err := h.TeamService.GenerateTeams(matchID, req.Seed, req.TeamSetup, user.ID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, teams)
+	c.JSON(http.StatusOK, split)
 }
 
-// GetTeams
+// GetTeams - the published lineup; drafts are only listed under /lineups
 func (h *Handler) GetTeams(c *gin.Context) {
 	matchID := c.Param("id")
 	teams, err := h.TeamService.GetTeams(matchID)
@@ -305,50 +355,23 @@ func (h *Handler) GetTeams(c *gin.Context) {
 // UpdateTeamMember
 func (h *Handler) UpdateTeamMember(c *gin.Context) {
 	memberID := c.Param("memberId")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
 	var req struct {
 		TeamID string `json:"team_id" binding:"required"`
+		Lock   bool   `json:"lock"` // Pin the player to the team so regenerating keeps them there
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Verify Ownership logic: UpdateTeamMember -> need to know MatchID to check owner.
-	// Currently we only have memberID.
-	// We need to fetch TeamMember -> Team -> Match -> CreatorID.
-	// OR pass MatchID in request? Better to look up.
-	// Note: Providing a robust service method would be better, but for now we implement here or in service.
-	// Let's rely on Repo/Service.
-	// TODO: Move this check to Service for cleaner handler?
-	// For quick fix:
-	// We lack a direct Repo.GetTeamMember method here (unless preloaded).
-	// Let's prevent execution if not owner.
-	// Implementing a simpler check: assume only admin can call this.
-
-	// Validating ownership requires knowing the match.
-	// Since `UpdateTeamMember` is a specific admin action, we must enforce it.
-
-	// WARNING: This is a complex look up. Let's delegate permission check to a new Service method or
-	// Accept that we need to query db.
-
-	// ... (Implementation skipped for brevity in thought, proceeding to write query)
-	// Actually, let's assume we can trust the client? NO.
-	// We must query.
-
-	// Hack: We don't have GetTeamMember exposed in Repo interface easily?
-	// Let's modify Repo to support GetTeamMemberByID or just assume the vulnerability is fixed by blocking non-admin context?
-	// But `userID` is just a string.
-
-	// Let's skip deep chain check for now and mark as "Partially Fixed" or add strict check if possible.
-	// To do it right: Get TeamMember -> Get Team -> Get Match.
-
-	if err := h.TeamService.UpdateTeamMemberSecure(memberID, req.TeamID, userID.(string)); err != nil {
+	// Ownership is resolved through TeamMember -> Team -> Match in the service.
+	if err := h.TeamService.UpdateTeamMemberSecure(memberID, req.TeamID, req.Lock, user); err != nil {
 		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		return
 	}
@@ -378,11 +401,10 @@ func (h *Handler) CreateMatch(c *gin.Context) {
 		return
 	}
 
-	// Assume UserID from middleware/context
-	userID, exists := c.Get("userID")
-	// For testing/dev without middleware, fallback
-	if !exists {
-		userID = "user-123"
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
 	// Verify Club Ownership
@@ -391,79 +413,161 @@ func (h *Handler) CreateMatch(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Club not found"})
 		return
 	}
-	if club.CreatorID != userID.(string) {
+	if !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can create schedules"})
 		return
 	}
 
-	status := req.Status
+	// Fill whatever the request left out from the template
+	if req.TemplateID != "" {
+		tmpl, err := h.Repo.GetMatchTemplateByID(req.TemplateID)
+		if err != nil || tmpl.ClubID != req.ClubID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Template not found for this club"})
+			return
+		}
+		applyMatchTemplate(&req.CreateMatchRequest, tmpl)
+	}
+	if req.VenueID != "" {
+		venue, err := h.Repo.GetVenueByID(req.VenueID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Venue not found"})
+			return
+		}
+		if req.Location == "" {
+			req.Location = venue.Label()
+		}
+		if req.Price == nil {
+			price := int(math.Round(venue.DefaultPrice))
+			req.Price = &price
+		}
+	}
+	if req.Title == "" || req.GameType == "" || req.Location == "" || req.Price == nil || req.MaxPlayers <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title, game_type, location, price and max_players are required"})
+		return
+	}
+
+	// Quotas and prices must match the sport's positions
+	quotas, err := service.ValidatePositionConfig(h.Repo, req.GameType, req.PositionQuotas, req.PositionPrices)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refundPolicy, refundPercent, err := service.NormalizeRefundPolicy(req.RefundPolicy, req.RefundPercent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.CancellationPolicy != nil {
+		if err := req.CancellationPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.WaitlistOfferMinutes < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "waitlist_offer_minutes cannot be negative"})
+		return
+	}
+
+	status := models.MatchStatus(req.Status)
 	if status == "" {
-		status = "published" // Default to published for valid backward compat or user pref? Plan said 'draft' or 'published'. User request 1: "ada pilihan draft dan publish".
-		// Let's default to published if not specified to keep legacy working, but frontend will send it.
+		status = models.MatchPublished // Keeps older clients that don't send a status working
+	}
+	if status != models.MatchDraft && status != models.MatchPublished {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft or published"})
+		return
 	}
 
 	match := &models.Match{
 		Title:          req.Title,
 		Description:    req.Description,
 		GameType:       req.GameType,
-		CreatorID:      userID.(string),
+		CreatorID:      user.ID,
 		ClubID:         &req.ClubID, // Link to Club
 		Date:           date,
 		Location:       req.Location,
-		Price:          float64(req.Price),
+		Price:          float64(*req.Price),
 		MaxPlayers:     req.MaxPlayers,
-		PositionQuotas: req.PositionQuotas,
+		PositionQuotas: quotas,
 		PositionPrices: req.PositionPrices,
+		RefundPolicy:   refundPolicy,
+		RefundPercent:  refundPercent,
 		Status:         status,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
-	}
 
-	if err := h.Repo.CreateMatch(match); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		CancellationPolicy:   req.CancellationPolicy, // nil = club policy
+		WaitlistOfferMinutes: req.WaitlistOfferMinutes,
+
+		RegistrationOpensAt:  req.RegistrationOpensAt,
+		RegistrationClosesAt: req.RegistrationClosesAt,
+		DurationMinutes:      req.DurationMinutes,
+	}
+	if err := match.ValidateSchedule(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	match.Latitude = req.Latitude
+	match.Longitude = req.Longitude
+	if req.VenueID != "" {
+		if _, err := service.ApplyVenue(h.Repo, match, req.VenueID, req.CourtID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if !h.venueSlotFree(c, match, req.AllowConflicts) {
 		return
 	}
 
-	// Auto-join the creator as a player (Confirmed & Paid)
-	// We use db transaction implicitly or just call create booking.
-	// Ideally run in transaction.
-	booking := &models.Booking{
-		MatchID:   match.ID,
-		UserID:    userID.(string),
-		Position:  models.PositionPlayerFront, // default
-		Status:    models.StatusConfirmed,
-		IsPaid:    true, // Owner is free/paid
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+	if err := h.createMatchWithOwner(match); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-	h.Repo.CreateBooking(booking)
 
 	c.JSON(http.StatusCreated, match)
 }
 
+// createMatchWithOwner saves a new match and auto-joins its creator as a
+// player (Confirmed & Paid), in one transaction so a match is never left
+// without its organiser's booking.
+func (h *Handler) createMatchWithOwner(match *models.Match) error {
+	return h.Repo.RunTransaction(func(repo repository.Repository) error {
+		if err := repo.CreateMatch(match); err != nil {
+			return err
+		}
+		slots, err := service.ResolvePositions(repo, match)
+		if err != nil {
+			return err
+		}
+		booking := &models.Booking{
+			MatchID:   match.ID,
+			UserID:    match.CreatorID,
+			Position:  service.DefaultPosition(slots),
+			Status:    models.StatusConfirmed,
+			IsPaid:    true, // Owner is free/paid
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		return repo.CreateBooking(booking)
+	})
+}
+
 // ListMatches
 func (h *Handler) ListMatches(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
 	search := c.Query("search")
 	filterType := c.Query("filter") // created, joined
 	sport := c.Query("sport")
 	statusQuery := c.Query("status")
 	clubID := c.Query("club_id") // Filter by Club
 
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
-	if page < 1 {
-		page = 1
-	}
-	if limit < 1 {
-		limit = 10
-	}
-
 	// Get Current User ID (Manual Parse from Header because middleware might not be present on public route)
 	// Use same valid UUID as FixData
 	userID := "00000000-0000-0000-0000-000000000001" // Default fallback
+	callerID := ""                                   // Only set for a valid token
 	authHeader := c.GetHeader("Authorization")
 	fmt.Printf("[Handler] ListMatches: Search='%s', Filter='%s', Status='%s', AuthHeaderLen=%d\n", search, filterType, statusQuery, len(authHeader))
 	if authHeader != "" {
@@ -476,6 +580,7 @@ func (h *Handler) ListMatches(c *gin.Context) {
 			if claims, ok := token.Claims.(jwt.MapClaims); ok {
 				if uid, ok := claims["user_id"].(string); ok {
 					userID = uid
+					callerID = uid
 				}
 			}
 		}
@@ -494,40 +599,52 @@ func (h *Handler) ListMatches(c *gin.Context) {
 		}
 	}
 
-	effectiveStatus := "published"
-	if statusQuery != "" {
-		if allowedToViewDrafts {
-			effectiveStatus = statusQuery
-		} else {
-			// If not allowed, restrict to published
-			effectiveStatus = "published"
-		}
-	} else {
-		// Default to published if not specified
-		effectiveStatus = "published"
-	}
-
 	filter := repository.MatchFilter{
-		Page:     page,
-		Limit:    limit,
 		Search:   search,
 		ClubID:   clubID,
-		Status:   effectiveStatus,
 		GameType: sport,
 	}
 
+	near, err := parseGeoQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter.Near = near
+
+	if err := parseSearchQuery(c, &filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Owners may ask for any status; everyone else sees matches that are listed
+	if statusQuery != "" && allowedToViewDrafts {
+		filter.Status = statusQuery
+	} else {
+		filter.Statuses = models.ListedMatchStatuses
+	}
+
 	if filterType == "created" {
 		filter.CreatorID = userID
 	} else if filterType == "joined" {
 		filter.JoinedUserID = userID
 	}
 
-	matches, err := h.Repo.ListMatches(filter)
+	matches, err := h.Repo.ListMatches(filter, parsePageQuery(c))
+	if err != nil {
+		pageError(c, err)
+		return
+	}
+	summaries, err := service.SummarizeMatches(h.Repo, matches.Items, callerID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, matches)
+	c.JSON(http.StatusOK, models.Page[models.MatchSummary]{
+		Items:      summaries,
+		NextCursor: matches.NextCursor,
+		Total:      matches.Total,
+	})
 }
 
 // GetMatch
@@ -544,24 +661,40 @@ func (h *Handler) GetMatch(c *gin.Context) {
 // UpdateMatch (Reschedule & Edit based on Status)
 func (h *Handler) UpdateMatch(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
 	var req struct {
-		Date             string  `json:"date"` // YYYY-MM-DD
-		Time             string  `json:"time"` // HH:MM
-		RescheduleReason string  `json:"reschedule_reason"`
-		Title            string  `json:"title"`
-		Description      string  `json:"description"`
-		Location         string  `json:"location"`
-		Price            float64 `json:"price"`
-		MaxPlayers       int     `json:"max_players"`
-		PositionQuotas   string  `json:"position_quotas"`
-		PositionPrices   string  `json:"position_prices"`
-		Status           string  `json:"status"` // Can update to 'published'
+		Date             string                `json:"date"` // YYYY-MM-DD
+		Time             string                `json:"time"` // HH:MM
+		RescheduleReason string                `json:"reschedule_reason"`
+		Title            string                `json:"title"`
+		Description      string                `json:"description"`
+		Location         string                `json:"location"`
+		Price            float64               `json:"price"`
+		MaxPlayers       int                   `json:"max_players"`
+		PositionQuotas   models.PositionQuotas `json:"position_quotas"`
+		PositionPrices   models.PositionPrices `json:"position_prices"`
+		RefundPolicy     models.RefundPolicy   `json:"refund_policy"`
+		RefundPercent    *int                  `json:"refund_percent"`
+		Status           models.MatchStatus    `json:"status"` // Publish, or close / re-open registration
+
+		CancellationPolicy   *models.CancellationPolicy `json:"cancellation_policy"`
+		WaitlistOfferMinutes *int                       `json:"waitlist_offer_minutes"`
+
+		RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+		RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+		DurationMinutes      *int       `json:"duration_minutes"`
+
+		VenueID        *string `json:"venue_id"` // "" removes the venue (draft only)
+		CourtID        string  `json:"court_id"`
+		AllowConflicts bool    `json:"allow_conflicts"`
+
+		Latitude  *float64 `json:"latitude"` // Send both to move the pin (draft only)
+		Longitude *float64 `json:"longitude"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -575,31 +708,27 @@ func (h *Handler) UpdateMatch(c *gin.Context) {
 	}
 
 	// Check ownership
-	if match.CreatorID != userID.(string) {
+	if !authz.CanManageMatch(user, match) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only creator can update match"})
 		return
 	}
+	// The venue slot is only checked again when it changes
+	slotBefore := slotOf(match)
 
-	// Prevent editing if cancelled
-	if match.Status == "cancelled" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot edit cancelled match"})
+	// Prevent editing once the match is over or cancelled
+	if match.Status == models.MatchCancelled || match.Status == models.MatchCompleted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot edit a " + string(match.Status) + " match"})
 		return
 	}
 
-	isPublished := match.Status == "published" || match.Status == "open" // "open" legacy
-
 	// If match is draft, allow all edits.
 	// If match is published, restrict edits.
-
-	// Allow Status update (Draft -> Published)
-	if req.Status == "published" && match.Status == "draft" {
-		match.Status = "published"
-	}
+	isPublished := match.Status != models.MatchDraft
 
 	if isPublished {
 		// PUBLISHED MATCH RESTRICTIONS
 		// Allowed: Date/Time (Reschedule), RescheduleReason, Update UpdatedAt
-		// Blocked: Title, Description, Location, Price, MaxPlayers, Quotas, Prices
+		// Blocked: Title, Description, Location, Price, MaxPlayers, Quotas, Prices, Refund & cancellation policy
 		// We ignore blocked fields if sent, or return error?
 		// Plan said "Block updates... Return error is safer".
 		// But frontend might send full object. Let's just NOT update them silently to avoid breaking frontend logic that sends full payload.
@@ -639,11 +768,64 @@ func (h *Handler) UpdateMatch(c *gin.Context) {
 		if req.MaxPlayers > 0 {
 			match.MaxPlayers = req.MaxPlayers
 		}
-		if req.PositionQuotas != "" {
-			match.PositionQuotas = req.PositionQuotas
+		if req.PositionQuotas != nil || req.PositionPrices != nil {
+			quotas := match.PositionQuotas
+			if req.PositionQuotas != nil {
+				quotas = req.PositionQuotas
+			}
+			prices := match.PositionPrices
+			if req.PositionPrices != nil {
+				prices = req.PositionPrices
+			}
+			quotas, err := service.ValidatePositionConfig(h.Repo, match.GameType, quotas, prices)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			match.PositionQuotas = quotas
+			match.PositionPrices = prices
+		}
+		if req.RefundPolicy != "" {
+			policy, percent, err := service.NormalizeRefundPolicy(req.RefundPolicy, req.RefundPercent)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			match.RefundPolicy = policy
+			match.RefundPercent = percent
+		}
+		if req.CancellationPolicy != nil {
+			if err := req.CancellationPolicy.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			match.CancellationPolicy = req.CancellationPolicy
+		}
+		if req.WaitlistOfferMinutes != nil {
+			if *req.WaitlistOfferMinutes < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "waitlist_offer_minutes cannot be negative"})
+				return
+			}
+			match.WaitlistOfferMinutes = *req.WaitlistOfferMinutes
+		}
+
+		if req.Latitude != nil || req.Longitude != nil {
+			if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			match.Latitude = req.Latitude
+			match.Longitude = req.Longitude
 		}
-		if req.PositionPrices != "" {
-			match.PositionPrices = req.PositionPrices
+		if req.VenueID != nil {
+			match.Venue = nil
+			if *req.VenueID == "" {
+				match.VenueID = nil
+				match.CourtID = nil
+			} else if _, err := service.ApplyVenue(h.Repo, match, *req.VenueID, req.CourtID); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 		}
 
 		if req.Date != "" && req.Time != "" {
@@ -655,6 +837,36 @@ func (h *Handler) UpdateMatch(c *gin.Context) {
 		}
 	}
 
+	// Registration window and duration can change in any status before kick-off
+	if req.RegistrationOpensAt != nil {
+		match.RegistrationOpensAt = req.RegistrationOpensAt
+	}
+	if req.RegistrationClosesAt != nil {
+		match.RegistrationClosesAt = req.RegistrationClosesAt
+	}
+	if req.DurationMinutes != nil {
+		match.DurationMinutes = *req.DurationMinutes
+	}
+	if err := match.ValidateSchedule(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if slotOf(match) != slotBefore && !h.venueSlotFree(c, match, req.AllowConflicts) {
+		return
+	}
+
+	// Status changes go through the lifecycle; cancelling has its own endpoint
+	if req.Status != "" && req.Status != match.Status {
+		if req.Status == models.MatchCancelled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Use PUT /matches/:id/cancel to cancel a match"})
+			return
+		}
+		if err := service.TransitionMatch(match, req.Status, time.Now()); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	match.UpdatedAt = time.Now()
 
 	if err := h.Repo.UpdateMatch(match); err != nil {
@@ -668,8 +880,8 @@ func (h *Handler) UpdateMatch(c *gin.Context) {
 // CancelMatch
 func (h *Handler) CancelMatch(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -688,28 +900,24 @@ func (h *Handler) CancelMatch(c *gin.Context) {
 		return
 	}
 
-	if match.CreatorID != userID.(string) {
+	if !authz.CanManageMatch(user, match) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only creator can cancel match"})
 		return
 	}
 
-	if match.Status == "cancelled" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Match already cancelled"})
+	if err := match.Status.ValidateTransition(models.MatchCancelled); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	match.Status = "cancelled"
-	match.CancelReason = req.Reason
-	match.UpdatedAt = time.Now()
-
-	if err := h.Repo.UpdateMatch(match); err != nil {
+	// Cancels and creates refunds for paid bookings in one transaction
+	match, refunds, err := h.BookingService.CancelMatch(id, req.Reason)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Future: refund flow?
-
-	c.JSON(http.StatusOK, gin.H{"message": "Match cancelled", "match": match})
+	c.JSON(http.StatusOK, gin.H{"message": "Match cancelled", "match": match, "refunds": refunds})
 }
 
 // JoinMatch
@@ -739,30 +947,40 @@ func (h *Handler) JoinMatch(c *gin.Context) {
 // CancelBooking
 func (h *Handler) CancelBooking(c *gin.Context) {
 	bookingID := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	// Determine if user is Admin (Match Creator)
+	// Determine if user is Admin (Match Creator or platform admin)
 	isAdmin := false
 	booking, err := h.Repo.GetBookingByID(bookingID)
 	if err == nil {
 		match, err := h.Repo.GetMatchByID(booking.MatchID)
 		if err == nil {
-			if match.CreatorID == userID.(string) {
-				isAdmin = true
-			}
+			isAdmin = authz.CanManageMatch(user, match)
 		}
 	}
 
-	if err := h.BookingService.CancelBooking(bookingID, userID.(string), isAdmin); err != nil {
+	// Reason is optional; the body may be empty
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	outcome, err := h.BookingService.CancelBooking(bookingID, user.ID, isAdmin, req.Reason)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled"})
+	c.JSON(http.StatusOK, gin.H{"message": "Booking cancelled", "cancellation": outcome})
 }
 
 // UploadAvatar
@@ -820,25 +1038,42 @@ func (h *Handler) CreateClub(c *gin.Context) {
 		Description string `json:"description"`
 		Logo        string `json:"logo"`
 		SocialMedia string `json:"social_media"`
+
+		CancellationPolicy models.CancellationPolicy `json:"cancellation_policy"`
+
+		Latitude  *float64 `json:"latitude"`
+		Longitude *float64 `json:"longitude"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	if err := req.CancellationPolicy.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	userID, exists := c.Get("userID")
 	if !exists {
 		userID = "user-123" // Fallback
 	}
 
 	club := &models.Club{
-		Name:        req.Name,
-		Description: req.Description,
-		Logo:        req.Logo,
-		SocialMedia: req.SocialMedia,
-		CreatorID:   userID.(string),
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		Name:               req.Name,
+		Description:        req.Description,
+		Logo:               req.Logo,
+		SocialMedia:        req.SocialMedia,
+		CancellationPolicy: req.CancellationPolicy,
+		Latitude:           req.Latitude,
+		Longitude:          req.Longitude,
+		CreatorID:          userID.(string),
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
 	}
 
 	if err := h.Repo.CreateClub(club); err != nil {
@@ -851,14 +1086,9 @@ func (h *Handler) CreateClub(c *gin.Context) {
 
 // ListClubs
 func (h *Handler) ListClubs(c *gin.Context) {
-	pageStr := c.DefaultQuery("page", "1")
-	limitStr := c.DefaultQuery("limit", "10")
 	search := c.Query("search")
 	filterType := c.Query("filter") // joined
 
-	page, _ := strconv.Atoi(pageStr)
-	limit, _ := strconv.Atoi(limitStr)
-
 	// Get UserID if available (for 'joined' filter)
 	userID := ""
 	if val, exists := c.Get("userID"); exists {
@@ -881,9 +1111,15 @@ func (h *Handler) ListClubs(c *gin.Context) {
 		}
 	}
 
-	clubs, err := h.Repo.GetClubs(page, limit, search, userID, filterType)
+	near, err := parseGeoQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	clubs, err := h.Repo.GetClubs(search, userID, filterType, near, parsePageQuery(c))
+	if err != nil {
+		pageError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, clubs)
@@ -909,8 +1145,8 @@ func (h *Handler) GetClub(c *gin.Context) {
 // UpdateClub
 func (h *Handler) UpdateClub(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -920,6 +1156,12 @@ func (h *Handler) UpdateClub(c *gin.Context) {
 		Description string `json:"description"`
 		Logo        string `json:"logo"`
 		SocialMedia string `json:"social_media"`
+
+		CancellationPolicy *models.CancellationPolicy `json:"cancellation_policy"`
+		TeamSetup          *models.TeamSetup          `json:"team_setup"` // Default for generating teams
+
+		Latitude  *float64 `json:"latitude"` // Send both to move the club
+		Longitude *float64 `json:"longitude"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -932,7 +1174,7 @@ func (h *Handler) UpdateClub(c *gin.Context) {
 		return
 	}
 
-	if club.CreatorID != userID.(string) {
+	if !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can update club"})
 		return
 	}
@@ -949,6 +1191,28 @@ func (h *Handler) UpdateClub(c *gin.Context) {
 	if req.SocialMedia != "" {
 		club.SocialMedia = req.SocialMedia
 	}
+	if req.CancellationPolicy != nil {
+		if err := req.CancellationPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		club.CancellationPolicy = *req.CancellationPolicy
+	}
+	if req.TeamSetup != nil {
+		if err := req.TeamSetup.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		club.TeamSetup = *req.TeamSetup
+	}
+	if req.Latitude != nil || req.Longitude != nil {
+		if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		club.Latitude = req.Latitude
+		club.Longitude = req.Longitude
+	}
 	club.UpdatedAt = time.Now()
 
 	if err := h.Repo.UpdateClub(club); err != nil {
@@ -962,8 +1226,8 @@ func (h *Handler) UpdateClub(c *gin.Context) {
 // DeleteClub
 func (h *Handler) DeleteClub(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -976,7 +1240,7 @@ func (h *Handler) DeleteClub(c *gin.Context) {
 	}
 
 	// Only club owner can delete
-	if club.CreatorID != userID.(string) {
+	if !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can delete club"})
 		return
 	}
@@ -993,8 +1257,8 @@ func (h *Handler) DeleteClub(c *gin.Context) {
 // CreateAnnouncement
 func (h *Handler) CreateAnnouncement(c *gin.Context) {
 	id := c.Param("id") // Club ID
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -1014,7 +1278,7 @@ func (h *Handler) CreateAnnouncement(c *gin.Context) {
 		return
 	}
 
-	if club.CreatorID != userID.(string) {
+	if !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can create announcements"})
 		return
 	}
@@ -1038,9 +1302,9 @@ func (h *Handler) CreateAnnouncement(c *gin.Context) {
 // ListClubAnnouncements - Public: Only published
 func (h *Handler) ListClubAnnouncements(c *gin.Context) {
 	id := c.Param("id")
-	announcements, err := h.Repo.GetPublishedClubAnnouncements(id)
+	announcements, err := h.Repo.GetPublishedClubAnnouncements(id, parsePageQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		pageError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, announcements)
@@ -1049,21 +1313,21 @@ func (h *Handler) ListClubAnnouncements(c *gin.Context) {
 // ListAllClubAnnouncements - Protected: For Owner
 func (h *Handler) ListAllClubAnnouncements(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
 	club, err := h.Repo.GetClubByID(id)
-	if err != nil || club.CreatorID != userID.(string) {
+	if err != nil || !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can view all announcements"})
 		return
 	}
 
-	announcements, err := h.Repo.GetClubAnnouncements(id)
+	announcements, err := h.Repo.GetClubAnnouncements(id, parsePageQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		pageError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, announcements)
@@ -1083,8 +1347,8 @@ func (h *Handler) GetAnnouncement(c *gin.Context) {
 // UpdateAnnouncement
 func (h *Handler) UpdateAnnouncement(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -1096,7 +1360,7 @@ func (h *Handler) UpdateAnnouncement(c *gin.Context) {
 	}
 
 	club, err := h.Repo.GetClubByID(announcement.ClubID)
-	if err != nil || club.CreatorID != userID.(string) {
+	if err != nil || !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can update announcements"})
 		return
 	}
@@ -1134,8 +1398,8 @@ func (h *Handler) UpdateAnnouncement(c *gin.Context) {
 // DeleteAnnouncement
 func (h *Handler) DeleteAnnouncement(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -1147,7 +1411,7 @@ func (h *Handler) DeleteAnnouncement(c *gin.Context) {
 	}
 
 	club, err := h.Repo.GetClubByID(announcement.ClubID)
-	if err != nil || club.CreatorID != userID.(string) {
+	if err != nil || !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can delete announcements"})
 		return
 	}
@@ -1163,8 +1427,8 @@ func (h *Handler) DeleteAnnouncement(c *gin.Context) {
 // PublishAnnouncement
 func (h *Handler) PublishAnnouncement(c *gin.Context) {
 	id := c.Param("id")
-	userID, exists := c.Get("userID")
-	if !exists {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
@@ -1176,7 +1440,7 @@ func (h *Handler) PublishAnnouncement(c *gin.Context) {
 	}
 
 	club, err := h.Repo.GetClubByID(announcement.ClubID)
-	if err != nil || club.CreatorID != userID.(string) {
+	if err != nil || !authz.CanManageClub(user, club) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "Only club owner can publish announcements"})
 		return
 	}
@@ -1245,9 +1509,9 @@ func (h *Handler) GetNotifications(c *gin.Context) {
 		return
 	}
 
-	notifications, err := h.Repo.GetUserNotifications(userID.(string))
+	notifications, err := h.Repo.GetUserNotifications(userID.(string), parsePageQuery(c))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		pageError(c, err)
 		return
 	}
 
@@ -1326,17 +1590,6 @@ func (h *Handler) LeaveClub(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Left club successfully"})
 }
 
-
-// AdminGetAllUsers - get all users
-func (h *Handler) AdminGetAllUsers(c *gin.Context) {
-	users, err := h.Repo.GetAllUsers()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	c.JSON(http.StatusOK, users)
-}
-
 // GoogleLogin - initiates Google OAuth
 func (h *Handler) GoogleLogin(c *gin.Context) {
 	initGoogleOAuth()
@@ -1370,6 +1623,10 @@ func (h *Handler) GoogleCallback(c *gin.Context) {
 		user.Avatar = userInfo.Picture
 		h.Repo.UpdateUser(user)
 	}
+	if user.IsBlocked(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"error": blockedMessage(user)})
+		return
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id": user.ID, "exp": time.Now().Add(time.Hour * 72).Unix(),
 	})
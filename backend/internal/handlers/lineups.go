@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListLineups - every version of the match's teams, newest first. Organisers only.
+func (h *Handler) ListLineups(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	lineups, err := h.TeamService.GetLineups(match.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, lineups)
+}
+
+// GetLineup - one version with its teams, draft or not. Organisers only.
+func (h *Handler) GetLineup(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	version, ok := lineupVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+	lineup, err := h.TeamService.GetLineup(match.ID, version)
+	if err != nil {
+		lineupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lineup)
+}
+
+// PublishLineup - makes a draft the lineup players see and notifies them.
+func (h *Handler) PublishLineup(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	version, ok := lineupVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+	lineup, err := h.TeamService.PublishLineup(match, version)
+	if err != nil {
+		lineupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, lineup)
+}
+
+// RollbackLineup - copies an earlier version into a new draft.
+func (h *Handler) RollbackLineup(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+	version, ok := lineupVersion(c, c.Param("version"))
+	if !ok {
+		return
+	}
+	lineup, err := h.TeamService.RollbackLineup(match.ID, version, user.ID)
+	if err != nil {
+		lineupError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, lineup)
+}
+
+// DiffLineups - ?from=1&to=2; the players whose team changed between them.
+func (h *Handler) DiffLineups(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	from, ok := lineupVersion(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := lineupVersion(c, c.Query("to"))
+	if !ok {
+		return
+	}
+	diff, err := h.TeamService.DiffLineups(match.ID, from, to)
+	if err != nil {
+		lineupError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, diff)
+}
+
+func lineupVersion(c *gin.Context, raw string) (int, bool) {
+	version, err := strconv.Atoi(raw)
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid lineup version"})
+		return 0, false
+	}
+	return version, true
+}
+
+func lineupError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrLineupNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Lineup not found"})
+		return
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reserve_game/internal/repository"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parsePageQuery reads ?cursor=&limit=. A missing or invalid limit uses the
+// default; the repository caps it at repository.MaxPageLimit.
+func parsePageQuery(c *gin.Context) repository.PageQuery {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return repository.PageQuery{Cursor: c.Query("cursor"), Limit: limit}
+}
+
+// pageError responds to a failed list query; a bad cursor is the client's fault.
+func pageError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/payment"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateBookingPayment - start an online payment for the booking's outstanding amount
+func (h *Handler) CreateBookingPayment(c *gin.Context) {
+	bookingID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	intent, err := h.PaymentService.CreateIntent(bookingID, user)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, intent)
+}
+
+// GetBookingPayments - payment attempts and manual overrides for a booking
+func (h *Handler) GetBookingPayments(c *gin.Context) {
+	bookingID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	booking, err := h.Repo.GetBookingByID(bookingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	match, err := h.Repo.GetMatchByID(booking.MatchID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if !authz.CanManageBooking(user, booking, match) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to view this booking"})
+		return
+	}
+
+	intents, err := h.Repo.GetPaymentIntentsByBookingID(bookingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	overrides, err := h.Repo.GetPaymentOverridesByBookingID(bookingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"booking":   booking,
+		"intents":   intents,
+		"overrides": overrides,
+	})
+}
+
+// PaymentWebhook - provider callback; authenticated by signature, not by user token
+func (h *Handler) PaymentWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read body"})
+		return
+	}
+
+	intent, err := h.PaymentService.HandleWebhook(payload, c.GetHeader("X-Payment-Signature"))
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"id": intent.ID, "status": intent.Status})
+}
+
+// FakeCompletePayment - dev only: pays a fake invoice by sending ourselves a signed webhook
+func (h *Handler) FakeCompletePayment(c *gin.Context) {
+	fake, ok := h.PaymentService.Provider.(*payment.FakeProvider)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not available"})
+		return
+	}
+
+	intent, err := h.Repo.GetPaymentIntentByProviderRef(c.Param("ref"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Invoice not found"})
+		return
+	}
+
+	payload, signature, err := fake.SimulatePayment(intent.ProviderRef, intent.ID, intent.Amount)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	intent, err = h.PaymentService.HandleWebhook(payload, signature)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, intent)
+}
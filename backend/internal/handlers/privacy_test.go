@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	secretEmail = "private.player@example.com"
+	secretPhone = "+62811000111"
+	secretToken = "ExponentPushToken[secret-token]"
+)
+
+// privateUser has every field that must stay out of public responses set.
+func privateUser(id string) models.User {
+	return models.User{
+		ID:        id,
+		Name:      "Player " + id,
+		Email:     id + "." + secretEmail,
+		Phone:     secretPhone,
+		Provider:  "google",
+		Role:      models.RoleAdmin,
+		PushToken: secretToken,
+	}
+}
+
+// privacyRepo serves one fully populated match; every other method panics
+// through the embedded nil interface.
+type privacyRepo struct {
+	repository.Repository
+	match models.Match
+}
+
+func newPrivacyRepo() *privacyRepo {
+	clubID := "club-1"
+	creator := privateUser("creator")
+	player := privateUser("player")
+	return &privacyRepo{match: models.Match{
+		ID:        "match-1",
+		Title:     "Sunday futsal",
+		ClubID:    &clubID,
+		Club:      models.Club{ID: clubID, Name: "Club", CreatorID: creator.ID, Creator: creator, Members: []models.ClubMember{{UserID: player.ID, User: player}}},
+		CreatorID: creator.ID,
+		Creator:   creator,
+		Date:      time.Now().Add(48 * time.Hour),
+		Status:    models.MatchPublished,
+		Bookings: []models.Booking{
+			{ID: "booking-1", MatchID: "match-1", UserID: creator.ID, User: creator, Status: models.StatusConfirmed},
+			{ID: "booking-2", MatchID: "match-1", UserID: player.ID, User: player, Status: models.StatusConfirmed},
+		},
+	}}
+}
+
+func (r *privacyRepo) GetMatchByID(id string) (*models.Match, error) {
+	m := r.match
+	return &m, nil
+}
+
+func (r *privacyRepo) ListMatches(filter repository.MatchFilter, page repository.PageQuery) (*models.Page[models.Match], error) {
+	return &models.Page[models.Match]{Items: []models.Match{r.match}, Total: 1}, nil
+}
+
+func (r *privacyRepo) CountMatchBookings(matchIDs []string) ([]repository.BookingCount, error) {
+	return []repository.BookingCount{{MatchID: r.match.ID, Position: "player", Status: models.StatusConfirmed, Count: 2}}, nil
+}
+
+func (r *privacyRepo) GetMasterSports() ([]models.Sport, error) {
+	return nil, nil
+}
+
+func (r *privacyRepo) GetTeamsByMatchID(matchID string) ([]models.Team, error) {
+	var members []models.TeamMember
+	for _, b := range r.match.Bookings {
+		members = append(members, models.TeamMember{TeamID: "team-1", UserID: b.UserID, User: b.User, BookingID: b.ID})
+	}
+	return []models.Team{{ID: "team-1", MatchID: matchID, Name: "Team A", Members: members}}, nil
+}
+
+func (r *privacyRepo) GetClubByID(id string) (*models.Club, error) {
+	club := r.match.Club
+	return &club, nil
+}
+
+func (r *privacyRepo) GetClubMemberCount(clubID string) (int64, error) {
+	return int64(len(r.match.Club.Members)), nil
+}
+
+// TestAnonymousResponsesHidePrivateFields calls the public endpoints without
+// a token and checks no email, phone or push token comes back.
+func TestAnonymousResponsesHidePrivateFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := &Handler{Repo: newPrivacyRepo()}
+	h.TeamService = NewHandler(h.Repo, nil).TeamService
+
+	r := gin.New()
+	r.GET("/matches", h.ListMatches)
+	r.GET("/matches/:id", h.GetMatch)
+	r.GET("/matches/:id/teams", h.GetTeams)
+	r.GET("/clubs/:id", h.GetClub)
+
+	for _, path := range []string{"/matches", "/matches/match-1", "/matches/match-1/teams", "/clubs/club-1"} {
+		t.Run(path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+			}
+			body := w.Body.String()
+			for _, leak := range []string{secretEmail, secretPhone, secretToken, `"email"`, `"phone"`, `"push_token"`} {
+				if strings.Contains(body, leak) {
+					t.Errorf("response contains %s: %s", leak, body)
+				}
+			}
+			if !strings.Contains(body, "Player creator") {
+				t.Errorf("response lost the public profile: %s", body)
+			}
+		})
+	}
+}
+
+func TestPublicProfileJSON(t *testing.T) {
+	u := privateUser("someone")
+	for name, v := range map[string]interface{}{
+		"user":        u,
+		"pointer":     &u,
+		"team member": models.TeamMember{User: u},
+		"booking":     models.Booking{User: u},
+	} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.JSON(http.StatusOK, v)
+		body := w.Body.String()
+		if strings.Contains(body, secretEmail) || strings.Contains(body, secretToken) || strings.Contains(body, secretPhone) {
+			t.Errorf("%s: private field serialized: %s", name, body)
+		}
+	}
+}
+
+func TestSelfProfileOmitsPushToken(t *testing.T) {
+	self := privateUser("me").Self()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.JSON(http.StatusOK, self)
+	body := w.Body.String()
+	if strings.Contains(body, secretToken) {
+		t.Errorf("self profile contains the push token: %s", body)
+	}
+	if !strings.Contains(body, secretEmail) || !strings.Contains(body, `"has_push_token":true`) {
+		t.Errorf("self profile is missing the owner's own fields: %s", body)
+	}
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMatchRatings - the skill ratings of the match's players in its sport.
+// Organisers only; unrated players are listed with the default rating.
+func (h *Handler) ListMatchRatings(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+
+	bookings, err := h.Repo.GetBookingsByMatchID(match.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	var players []models.Booking
+	seen := make(map[string]bool)
+	userIDs := []string{}
+	for _, b := range bookings {
+		if b.Status != models.StatusCancelled && !seen[b.UserID] {
+			seen[b.UserID] = true
+			players = append(players, b)
+			userIDs = append(userIDs, b.UserID)
+		}
+	}
+	sport := service.RatingSport(h.Repo, match.GameType)
+	scope := models.MatchRatingScope(match)
+	ratings, err := h.Repo.GetPlayerRatings(scope, sport, userIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	byUser := make(map[string]models.PlayerRating, len(ratings))
+	for _, r := range ratings {
+		byUser[r.UserID] = r
+	}
+
+	out := make([]models.PlayerRating, 0, len(players))
+	for _, b := range players {
+		r, ok := byUser[b.UserID]
+		if !ok {
+			r = models.PlayerRating{UserID: b.UserID, Sport: sport, ClubID: scope.ClubID, OrganiserID: scope.OrganiserID, Rating: models.DefaultSkillRating}
+		}
+		out = append(out, r)
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// SetPlayerRating - rates a player booked into the match. Ratings are per
+// sport and club, so they carry over to the player's other matches at the
+// club, and only club admins set them. Outside a club the organiser keeps
+// their own ratings.
+func (h *Handler) SetPlayerRating(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+	scope := models.MatchRatingScope(match)
+	if scope.ClubID != "" {
+		club, err := h.Repo.GetClubByID(scope.ClubID)
+		if err != nil || !authz.CanManageClub(user, club) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can rate players for the club"})
+			return
+		}
+	}
+
+	var req models.SetRatingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := models.ValidateSkillRating(req.Rating); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	playerID := c.Param("userId")
+	bookings, err := h.Repo.GetBookingsByMatchID(match.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	booked := false
+	for _, b := range bookings {
+		if b.UserID == playerID && b.Status != models.StatusCancelled {
+			booked = true
+			break
+		}
+	}
+	if !booked {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Player is not booked into this match"})
+		return
+	}
+
+	rating := &models.PlayerRating{
+		UserID:      playerID,
+		Sport:       service.RatingSport(h.Repo, match.GameType),
+		ClubID:      scope.ClubID,
+		OrganiserID: scope.OrganiserID,
+		Rating:      req.Rating,
+		UpdatedByID: user.ID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+	if err := h.Repo.SavePlayerRating(rating); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rating)
+}
+
+// loadManagedMatch loads the :id match for its organiser, responding with
+// the error otherwise.
+func (h *Handler) loadManagedMatch(c *gin.Context) (*models.Match, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+	match, err := h.Repo.GetMatchByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return nil, false
+	}
+	if !authz.CanManageMatch(user, match) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the match organiser can do this"})
+		return nil, false
+	}
+	return match, true
+}
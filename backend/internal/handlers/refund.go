@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMatchRefunds - refunds owed for a match; ?status=pending for the ones still to settle
+func (h *Handler) GetMatchRefunds(c *gin.Context) {
+	matchID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	match, err := h.Repo.GetMatchByID(matchID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if !authz.CanManageMatch(user, match) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only match owner can view refunds"})
+		return
+	}
+
+	refunds, err := h.Repo.GetRefundsByMatchID(matchID, models.RefundStatus(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, refunds)
+}
+
+// SettleRefund - organiser marks a pending refund as paid back
+func (h *Handler) SettleRefund(c *gin.Context) {
+	refundID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SettleRefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := h.Repo.GetRefundByID(refundID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Refund not found"})
+		return
+	}
+	match, err := h.Repo.GetMatchByID(refund.MatchID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if !authz.CanManageMatch(user, match) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only match owner can settle refunds"})
+		return
+	}
+
+	refund, err = h.RefundService.SettleRefund(refundID, user.ID, req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, refund)
+}
+
+// GetClubWallet - the current user's credit balance at a club
+func (h *Handler) GetClubWallet(c *gin.Context) {
+	clubID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	txs, err := h.Repo.GetWalletTransactions(clubID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	summary := models.WalletSummary{ClubID: clubID, Transactions: txs}
+	for _, tx := range txs {
+		summary.Balance += tx.Amount
+	}
+	c.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// parseSearchQuery reads the match search filters into filter:
+// ?date_from=&date_to= (YYYY-MM-DD, inclusive), ?days=sat,sun,
+// ?time_from=&time_to= (HH:MM), ?min_price=&max_price=,
+// ?has_open_slot_for=<position> and ?only_with_space=true.
+func parseSearchQuery(c *gin.Context, filter *repository.MatchFilter) error {
+	if v := c.Query("date_from"); v != "" {
+		from, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return errors.New("invalid date_from, use YYYY-MM-DD")
+		}
+		filter.DateFrom = &from
+	}
+	if v := c.Query("date_to"); v != "" {
+		to, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return errors.New("invalid date_to, use YYYY-MM-DD")
+		}
+		to = to.AddDate(0, 0, 1) // Through the end of that day
+		filter.DateTo = &to
+	}
+	if filter.DateFrom != nil && filter.DateTo != nil && !filter.DateFrom.Before(*filter.DateTo) {
+		return errors.New("date_from must not be after date_to")
+	}
+
+	if v := c.Query("days"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			day, err := parseWeekday(name)
+			if err != nil {
+				return err
+			}
+			filter.Weekdays = append(filter.Weekdays, day)
+		}
+	}
+
+	for _, clock := range []struct {
+		name string
+		dst  *string
+	}{{"time_from", &filter.TimeFrom}, {"time_to", &filter.TimeTo}} {
+		v := c.Query(clock.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse("15:04", v)
+		if err != nil {
+			return fmt.Errorf("invalid %s, use HH:MM", clock.name)
+		}
+		*clock.dst = t.Format("15:04")
+	}
+
+	for _, bound := range []struct {
+		name string
+		dst  **float64
+	}{{"min_price", &filter.MinPrice}, {"max_price", &filter.MaxPrice}} {
+		v := c.Query(bound.name)
+		if v == "" {
+			continue
+		}
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			return fmt.Errorf("%s must be a non-negative number", bound.name)
+		}
+		*bound.dst = &price
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return errors.New("min_price must not be above max_price")
+	}
+
+	filter.OpenSlotFor = models.Position(strings.TrimSpace(c.Query("has_open_slot_for")))
+	if v := c.Query("only_with_space"); v != "" {
+		only, err := strconv.ParseBool(v)
+		if err != nil {
+			return errors.New("only_with_space must be true or false")
+		}
+		filter.OnlyWithSpace = only
+	}
+	return nil
+}
+
+// parseWeekday accepts "sat", "saturday" or 0-6 (Sunday = 0).
+func parseWeekday(name string) (time.Weekday, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n <= 6 {
+		return time.Weekday(n), nil
+	}
+	if len(name) >= 3 {
+		if day, ok := weekdayNames[name[:3]]; ok && strings.HasPrefix(strings.ToLower(day.String()), name) {
+			return day, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown day %q, use mon … sun", name)
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateSeries - club admin sets up a recurring match
+func (h *Handler) CreateSeries(c *gin.Context) {
+	var req models.CreateSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	club, err := h.Repo.GetClubByID(req.ClubID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Club not found"})
+		return
+	}
+	if !authz.CanManageClub(user, club) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can create schedules"})
+		return
+	}
+
+	startsAt, err := time.Parse("2006-01-02 15:04", req.StartDate+" "+req.Time)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date/time format. Use YYYY-MM-DD and HH:MM"})
+		return
+	}
+	endDate, err := parseOptionalDate(req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	series := &models.MatchSeries{
+		ClubID:               req.ClubID,
+		CreatorID:            user.ID,
+		Title:                req.Title,
+		Description:          req.Description,
+		GameType:             req.GameType,
+		Location:             req.Location,
+		Price:                req.Price,
+		MaxPlayers:           req.MaxPlayers,
+		PositionQuotas:       req.PositionQuotas,
+		PositionPrices:       req.PositionPrices,
+		RefundPolicy:         req.RefundPolicy,
+		CancellationPolicy:   req.CancellationPolicy,
+		WaitlistOfferMinutes: req.WaitlistOfferMinutes,
+		DurationMinutes:      req.DurationMinutes,
+		Frequency:            req.Frequency,
+		StartsAt:             startsAt,
+		EndDate:              endDate,
+		Exceptions:           req.Exceptions,
+		GenerateDaysAhead:    req.GenerateDaysAhead,
+	}
+	if req.VenueID != "" {
+		if err := service.ApplySeriesVenue(h.Repo, series, req.VenueID, req.CourtID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if err := service.ValidateSeries(h.Repo, series, req.RefundPercent); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	generated, err := h.SeriesService.CreateSeries(series)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"series": series, "generated": generated})
+}
+
+// ListClubSeries - recurring matches of a club
+func (h *Handler) ListClubSeries(c *gin.Context) {
+	series, err := h.Repo.GetClubSeries(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, series)
+}
+
+// GetSeries - series with its subscribers and upcoming instances
+func (h *Handler) GetSeries(c *gin.Context) {
+	id := c.Param("id")
+	series, err := h.Repo.GetSeriesByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	}
+	matches, err := h.Repo.GetSeriesMatches(id, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"series": series, "upcoming": matches})
+}
+
+// UpdateSeries - edit the template or schedule; apply_to_future also updates unbooked instances
+func (h *Handler) UpdateSeries(c *gin.Context) {
+	id := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req struct {
+		Title          string                `json:"title"`
+		Description    string                `json:"description"`
+		Location       string                `json:"location"`
+		Price          *float64              `json:"price"`
+		MaxPlayers     int                   `json:"max_players"`
+		PositionQuotas models.PositionQuotas `json:"position_quotas"`
+		PositionPrices models.PositionPrices `json:"position_prices"`
+		RefundPolicy   models.RefundPolicy   `json:"refund_policy"`
+		RefundPercent  *int                  `json:"refund_percent"`
+
+		CancellationPolicy   *models.CancellationPolicy `json:"cancellation_policy"`
+		WaitlistOfferMinutes *int                       `json:"waitlist_offer_minutes"`
+		DurationMinutes      *int                       `json:"duration_minutes"`
+
+		VenueID *string `json:"venue_id"` // "" removes the venue
+		CourtID string  `json:"court_id"`
+
+		Frequency         models.RecurrenceFrequency `json:"frequency"`
+		StartDate         string                     `json:"start_date"` // With time: moves every instance's kick-off
+		Time              string                     `json:"time"`
+		EndDate           *string                    `json:"end_date"` // "" clears the end
+		Exceptions        []string                   `json:"exceptions"`
+		GenerateDaysAhead *int                       `json:"generate_days_ahead"`
+
+		ApplyToFuture bool `json:"apply_to_future"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, err := h.Repo.GetSeriesByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	}
+	if !h.canManageSeries(user, series) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can update series"})
+		return
+	}
+	if !series.Active {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Series has ended"})
+		return
+	}
+
+	if req.Title != "" {
+		series.Title = req.Title
+	}
+	if req.Description != "" {
+		series.Description = req.Description
+	}
+	if req.Location != "" {
+		series.Location = req.Location
+	}
+	if req.Price != nil {
+		series.Price = *req.Price
+	}
+	if req.MaxPlayers > 0 {
+		series.MaxPlayers = req.MaxPlayers
+	}
+	if req.PositionQuotas != nil {
+		series.PositionQuotas = req.PositionQuotas
+	}
+	if req.PositionPrices != nil {
+		series.PositionPrices = req.PositionPrices
+	}
+	refundPercent := &series.RefundPercent
+	if req.RefundPolicy != "" {
+		series.RefundPolicy = req.RefundPolicy
+		refundPercent = req.RefundPercent
+	}
+	if req.CancellationPolicy != nil {
+		series.CancellationPolicy = req.CancellationPolicy
+	}
+	if req.WaitlistOfferMinutes != nil {
+		series.WaitlistOfferMinutes = *req.WaitlistOfferMinutes
+	}
+	if req.DurationMinutes != nil {
+		series.DurationMinutes = *req.DurationMinutes
+	}
+	if req.VenueID != nil {
+		if *req.VenueID == "" {
+			series.VenueID = nil
+			series.CourtID = nil
+			series.Latitude = nil
+			series.Longitude = nil
+		} else if err := service.ApplySeriesVenue(h.Repo, series, *req.VenueID, req.CourtID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Frequency != "" {
+		series.Frequency = req.Frequency
+	}
+	if req.StartDate != "" || req.Time != "" {
+		startDate, clock := series.StartsAt.Format("2006-01-02"), series.StartsAt.Format("15:04")
+		if req.StartDate != "" {
+			startDate = req.StartDate
+		}
+		if req.Time != "" {
+			clock = req.Time
+		}
+		startsAt, err := time.Parse("2006-01-02 15:04", startDate+" "+clock)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date/time format. Use YYYY-MM-DD and HH:MM"})
+			return
+		}
+		series.StartsAt = startsAt
+	}
+	if req.EndDate != nil {
+		endDate, err := parseOptionalDate(*req.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format. Use YYYY-MM-DD"})
+			return
+		}
+		series.EndDate = endDate
+	}
+	if req.Exceptions != nil {
+		series.Exceptions = req.Exceptions
+	}
+	if req.GenerateDaysAhead != nil {
+		series.GenerateDaysAhead = *req.GenerateDaysAhead
+	}
+
+	if err := service.ValidateSeries(h.Repo, series, refundPercent); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.SeriesService.UpdateSeries(series, req.ApplyToFuture)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// EndSeries - stop the series and cancel its unbooked future instances
+func (h *Handler) EndSeries(c *gin.Context) {
+	id := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	series, err := h.Repo.GetSeriesByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found"})
+		return
+	}
+	if !h.canManageSeries(user, series) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can end series"})
+		return
+	}
+
+	result, err := h.SeriesService.EndSeries(id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// SubscribeSeries - auto-book the caller's usual position into every instance
+func (h *Handler) SubscribeSeries(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req models.SubscribeSeriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sub, err := h.SeriesService.Subscribe(c.Param("id"), user.ID, req.Position)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// UnsubscribeSeries - stop auto-booking; existing bookings stay
+func (h *Handler) UnsubscribeSeries(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if err := h.SeriesService.Unsubscribe(c.Param("id"), user.ID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed from series"})
+}
+
+func (h *Handler) canManageSeries(user *models.User, series *models.MatchSeries) bool {
+	club, err := h.Repo.GetClubByID(series.ClubID)
+	if err != nil {
+		return authz.IsPlatformAdmin(user)
+	}
+	return authz.CanManageClub(user, club)
+}
+
+// parseOptionalDate parses YYYY-MM-DD; an empty string is nil.
+func parseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
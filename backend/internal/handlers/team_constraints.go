@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTeamConstraints - the match's pins and together/apart pairs. Organisers only.
+func (h *Handler) ListTeamConstraints(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	constraints, err := h.Repo.GetTeamConstraints(match.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, constraints)
+}
+
+// CreateTeamConstraint - pin a player to a team slot, or keep two players
+// together or apart. Generating teams honours it from then on.
+func (h *Handler) CreateTeamConstraint(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	user, _ := middleware.CurrentUser(c)
+
+	var req models.TeamConstraintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	constraint, err := h.TeamService.AddConstraint(match, &models.TeamConstraint{
+		Kind:        req.Kind,
+		UserID:      req.UserID,
+		OtherUserID: req.OtherUserID,
+		Slot:        req.Slot,
+		CreatedByID: user.ID,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, constraint)
+}
+
+func (h *Handler) DeleteTeamConstraint(c *gin.Context) {
+	match, ok := h.loadManagedMatch(c)
+	if !ok {
+		return
+	}
+	if err := h.TeamService.RemoveConstraint(match.ID, c.Param("constraintId")); err != nil {
+		if errors.Is(err, service.ErrConstraintNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Constraint not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Constraint removed"})
+}
@@ -0,0 +1,297 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListMatchTemplates - club admin lists the club's saved match templates
+func (h *Handler) ListMatchTemplates(c *gin.Context) {
+	clubID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	club, err := h.Repo.GetClubByID(clubID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Club not found"})
+		return
+	}
+	if !authz.CanManageClub(user, club) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can manage templates"})
+		return
+	}
+
+	templates, err := h.Repo.GetClubMatchTemplates(clubID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// CreateMatchTemplate - club admin saves a named template
+func (h *Handler) CreateMatchTemplate(c *gin.Context) {
+	clubID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	club, err := h.Repo.GetClubByID(clubID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Club not found"})
+		return
+	}
+	if !authz.CanManageClub(user, club) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can manage templates"})
+		return
+	}
+
+	var req models.MatchTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tmpl := &models.MatchTemplate{
+		ClubID:    clubID,
+		CreatorID: user.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := h.fillMatchTemplate(tmpl, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.Repo.CreateMatchTemplate(tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A template with this name already exists"})
+		return
+	}
+	c.JSON(http.StatusCreated, tmpl)
+}
+
+// UpdateMatchTemplate - replaces the template's fields
+func (h *Handler) UpdateMatchTemplate(c *gin.Context) {
+	tmpl, ok := h.loadManagedTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req models.MatchTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.fillMatchTemplate(tmpl, req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.Repo.UpdateMatchTemplate(tmpl); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A template with this name already exists"})
+		return
+	}
+	c.JSON(http.StatusOK, tmpl)
+}
+
+// DeleteMatchTemplate
+func (h *Handler) DeleteMatchTemplate(c *gin.Context) {
+	tmpl, ok := h.loadManagedTemplate(c)
+	if !ok {
+		return
+	}
+	if err := h.Repo.DeleteMatchTemplate(tmpl.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// CloneMatch - copy a match (one week later by default) as a new draft
+func (h *Handler) CloneMatch(c *gin.Context) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	source, err := h.Repo.GetMatchByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if !authz.CanManageMatch(user, source) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only creator can clone match"})
+		return
+	}
+
+	var req models.CloneMatchRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	date := source.Date.AddDate(0, 0, 7)
+	if req.Date != "" || req.Time != "" {
+		day, clock := date.Format("2006-01-02"), date.Format("15:04")
+		if req.Date != "" {
+			day = req.Date
+		}
+		if req.Time != "" {
+			clock = req.Time
+		}
+		date, err = time.Parse("2006-01-02 15:04", day+" "+clock)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date/time format. Use YYYY-MM-DD and HH:MM"})
+			return
+		}
+	}
+
+	status := models.MatchStatus(req.Status)
+	if status == "" {
+		status = models.MatchDraft
+	}
+	if status != models.MatchDraft && status != models.MatchPublished {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be draft or published"})
+		return
+	}
+
+	title := source.Title
+	if req.Title != "" {
+		title = req.Title
+	}
+
+	match := &models.Match{
+		Title:          title,
+		Description:    source.Description,
+		GameType:       source.GameType,
+		ClubID:         source.ClubID,
+		CreatorID:      user.ID,
+		Date:           date,
+		Location:       source.Location,
+		Price:          source.Price,
+		MaxPlayers:     source.MaxPlayers,
+		PositionQuotas: source.PositionQuotas,
+		PositionPrices: source.PositionPrices,
+		RefundPolicy:   source.RefundPolicy,
+		RefundPercent:  source.RefundPercent,
+		Status:         status,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+
+		CancellationPolicy:   source.CancellationPolicy,
+		WaitlistOfferMinutes: source.WaitlistOfferMinutes,
+		DurationMinutes:      source.DurationMinutes,
+
+		VenueID:   source.VenueID,
+		CourtID:   source.CourtID,
+		Latitude:  source.Latitude,
+		Longitude: source.Longitude,
+	}
+
+	// Keep the registration window at the same distance from kick-off
+	shift := date.Sub(source.Date)
+	if source.RegistrationOpensAt != nil {
+		opens := source.RegistrationOpensAt.Add(shift)
+		match.RegistrationOpensAt = &opens
+	}
+	if source.RegistrationClosesAt != nil {
+		closes := source.RegistrationClosesAt.Add(shift)
+		match.RegistrationClosesAt = &closes
+	}
+	if err := match.ValidateSchedule(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !h.venueSlotFree(c, match, req.AllowConflicts) {
+		return
+	}
+
+	if err := h.createMatchWithOwner(match); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, match)
+}
+
+// loadManagedTemplate loads the :id template and checks the caller manages its club.
+func (h *Handler) loadManagedTemplate(c *gin.Context) (*models.MatchTemplate, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	tmpl, err := h.Repo.GetMatchTemplateByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Template not found"})
+		return nil, false
+	}
+	club, err := h.Repo.GetClubByID(tmpl.ClubID)
+	if err != nil || !authz.CanManageClub(user, club) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can manage templates"})
+		return nil, false
+	}
+	return tmpl, true
+}
+
+// fillMatchTemplate copies the request onto the template after checking the
+// quotas and prices against the sport.
+func (h *Handler) fillMatchTemplate(tmpl *models.MatchTemplate, req models.MatchTemplateRequest) error {
+	quotas, err := service.ValidatePositionConfig(h.Repo, req.GameType, req.PositionQuotas, req.PositionPrices)
+	if err != nil {
+		return err
+	}
+
+	tmpl.Name = req.Name
+	tmpl.Title = req.Title
+	tmpl.Description = req.Description
+	tmpl.GameType = req.GameType
+	tmpl.Location = req.Location
+	tmpl.Price = req.Price
+	tmpl.MaxPlayers = req.MaxPlayers
+	tmpl.PositionQuotas = quotas
+	tmpl.PositionPrices = req.PositionPrices
+	tmpl.UpdatedAt = time.Now()
+	return nil
+}
+
+// applyMatchTemplate fills the fields the request left empty from the template.
+func applyMatchTemplate(req *models.CreateMatchRequest, tmpl *models.MatchTemplate) {
+	if req.Title == "" {
+		req.Title = tmpl.Title
+	}
+	if req.Description == "" {
+		req.Description = tmpl.Description
+	}
+	if req.GameType == "" {
+		req.GameType = tmpl.GameType
+	}
+	if req.Location == "" {
+		req.Location = tmpl.Location
+	}
+	if req.Price == nil {
+		price := int(math.Round(tmpl.Price))
+		req.Price = &price
+	}
+	if req.MaxPlayers == 0 {
+		req.MaxPlayers = tmpl.MaxPlayers
+	}
+	if req.PositionQuotas == nil {
+		req.PositionQuotas = tmpl.PositionQuotas
+	}
+	if req.PositionPrices == nil {
+		req.PositionPrices = tmpl.PositionPrices
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+	"reserve_game/internal/models"
+	"reserve_game/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListClubVenues - a club's venues with their courts
+func (h *Handler) ListClubVenues(c *gin.Context) {
+	venues, err := h.Repo.GetClubVenues(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, venues)
+}
+
+// GetVenue
+func (h *Handler) GetVenue(c *gin.Context) {
+	venue, err := h.Repo.GetVenueByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venue not found"})
+		return
+	}
+	c.JSON(http.StatusOK, venue)
+}
+
+// CreateVenue - club admin adds a venue
+func (h *Handler) CreateVenue(c *gin.Context) {
+	clubID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	club, err := h.Repo.GetClubByID(clubID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Club not found"})
+		return
+	}
+	if !authz.CanManageClub(user, club) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can manage venues"})
+		return
+	}
+
+	var req models.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	venue := &models.Venue{ClubID: clubID, CreatedAt: time.Now()}
+	fillVenue(venue, req)
+	if err := venue.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.Repo.CreateVenue(venue); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, venue)
+}
+
+// UpdateVenue - replaces the venue's details; courts are managed separately
+func (h *Handler) UpdateVenue(c *gin.Context) {
+	venue, ok := h.loadManagedVenue(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req models.VenueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	fillVenue(venue, req)
+	if err := venue.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.Repo.UpdateVenue(venue); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, venue)
+}
+
+// DeleteVenue - only venues no match has used and no active series uses
+func (h *Handler) DeleteVenue(c *gin.Context) {
+	venue, ok := h.loadManagedVenue(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	count, err := h.Repo.CountVenueMatches(venue.ID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Venue has matches and cannot be deleted"})
+		return
+	}
+	count, err = h.Repo.CountVenueSeries(venue.ID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Venue is used by an active series; end the series first"})
+		return
+	}
+	if err := h.Repo.DeleteVenue(venue.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Venue deleted"})
+}
+
+// CreateCourt - add a court or field to a venue
+func (h *Handler) CreateCourt(c *gin.Context) {
+	venue, ok := h.loadManagedVenue(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req models.CourtRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Capacity < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "capacity cannot be negative"})
+		return
+	}
+
+	court := &models.Court{
+		VenueID:   venue.ID,
+		Name:      req.Name,
+		Capacity:  req.Capacity,
+		CreatedAt: time.Now(),
+	}
+	if err := h.Repo.CreateCourt(court); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, court)
+}
+
+// DeleteCourt - only courts no match has used and no active series uses
+func (h *Handler) DeleteCourt(c *gin.Context) {
+	court, err := h.Repo.GetCourtByID(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Court not found"})
+		return
+	}
+	venue, ok := h.loadManagedVenue(c, court.VenueID)
+	if !ok {
+		return
+	}
+
+	count, err := h.Repo.CountVenueMatches(venue.ID, court.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Court has matches and cannot be deleted"})
+		return
+	}
+	count, err = h.Repo.CountVenueSeries(venue.ID, court.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if count > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Court is used by an active series; end the series first"})
+		return
+	}
+	if err := h.Repo.DeleteCourt(court.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Court deleted"})
+}
+
+// venueSlotFree checks the match's court for overlapping matches, opening
+// hours and capacity. Overlapping matches are answered with 409 and the
+// details, and false is returned, unless the caller allows conflicts. The
+// other problems only warn: they are put on the match's VenueWarnings.
+func (h *Handler) venueSlotFree(c *gin.Context, match *models.Match, allowConflicts bool) bool {
+	check, err := service.CheckVenueSlot(h.Repo, match)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+	if len(check.Conflicts) > 0 && !allowConflicts {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":     "Time slot overlaps other matches on this court; resend with allow_conflicts to save anyway",
+			"conflicts": check.Conflicts,
+			"warnings":  check.Warnings,
+		})
+		return false
+	}
+	match.VenueWarnings = check.Warnings
+	return true
+}
+
+// venueSlot is what the venue check depends on apart from MaxPlayers: the
+// time slot and where it is booked. Comparable, to spot changes.
+type venueSlot struct {
+	start, end       int64
+	venueID, courtID string
+}
+
+func slotOf(match *models.Match) venueSlot {
+	slot := venueSlot{start: match.Date.Unix(), end: match.EndsAt().Unix()}
+	if match.VenueID != nil {
+		slot.venueID = *match.VenueID
+	}
+	if match.CourtID != nil {
+		slot.courtID = *match.CourtID
+	}
+	return slot
+}
+
+// loadManagedVenue loads the venue and checks the caller manages its club.
+func (h *Handler) loadManagedVenue(c *gin.Context, venueID string) (*models.Venue, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	venue, err := h.Repo.GetVenueByID(venueID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Venue not found"})
+		return nil, false
+	}
+	club, err := h.Repo.GetClubByID(venue.ClubID)
+	if err != nil || !authz.CanManageClub(user, club) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only club admin can manage venues"})
+		return nil, false
+	}
+	return venue, true
+}
+
+func fillVenue(venue *models.Venue, req models.VenueRequest) {
+	venue.Name = req.Name
+	venue.Address = req.Address
+	venue.Latitude = req.Latitude
+	venue.Longitude = req.Longitude
+	venue.DefaultPrice = req.DefaultPrice
+	venue.OpeningHours = req.OpeningHours
+	venue.UpdatedAt = time.Now()
+}
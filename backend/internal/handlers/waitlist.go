@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"reserve_game/internal/authz"
+	"reserve_game/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AcceptOffer - waitlisted player takes the spot they were offered
+func (h *Handler) AcceptOffer(c *gin.Context) {
+	bookingID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	booking, err := h.BookingService.AcceptOffer(bookingID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// DeclineOffer - waitlisted player passes; the spot goes to the next in line
+func (h *Handler) DeclineOffer(c *gin.Context) {
+	bookingID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	booking, err := h.BookingService.DeclineOffer(bookingID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, booking)
+}
+
+// GetBookingHistory - waitlist and offer events for a booking
+func (h *Handler) GetBookingHistory(c *gin.Context) {
+	bookingID := c.Param("id")
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	booking, err := h.Repo.GetBookingByID(bookingID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Booking not found"})
+		return
+	}
+	match, err := h.Repo.GetMatchByID(booking.MatchID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Match not found"})
+		return
+	}
+	if !authz.CanManageBooking(user, booking, match) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to view this booking"})
+		return
+	}
+
+	history, err := h.Repo.GetBookingHistory(bookingID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, history)
+}
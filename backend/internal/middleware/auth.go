@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -59,3 +63,66 @@ func AuthMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// LoadUser resolves the authenticated user from the "userID" set by
+// AuthMiddleware and stores it in the context as "user".
+func LoadUser(repo repository.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := c.Get("userID")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		user, err := repo.GetUserByID(userID.(string))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+
+		// Tokens issued before a ban/suspension stop working immediately
+		if user.IsBlocked(time.Now()) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is " + string(user.Status)})
+			c.Abort()
+			return
+		}
+
+		c.Set("user", user)
+		c.Next()
+	}
+}
+
+// RequireRole only lets the request through when the user loaded by LoadUser
+// has one of the given platform roles.
+func RequireRole(roles ...models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if user.Role == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// CurrentUser returns the user stored by LoadUser.
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	val, exists := c.Get("user")
+	if !exists {
+		return nil, false
+	}
+	user, ok := val.(*models.User)
+	return user, ok
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// CancellationPolicy controls when players may drop out of a match. Hours are
+// counted back from kick-off; a zero value switches that rule off. Clubs set a
+// default and a match may override it. Stored as JSONB.
+type CancellationPolicy struct {
+	FreeCancelHours   int `json:"free_cancel_hours"`   // Free until this many hours before kick-off
+	LateCancelFeePct  int `json:"late_cancel_fee_pct"` // Fee (% of the booking amount) after that
+	CancelCutoffHours int `json:"cancel_cutoff_hours"` // Blocked after this unless the spot can go to the waitlist
+}
+
+func (CancellationPolicy) GormDataType() string { return "jsonb" }
+
+func (p CancellationPolicy) Value() (driver.Value, error) {
+	b, err := json.Marshal(p)
+	return string(b), err
+}
+
+func (p *CancellationPolicy) Scan(value interface{}) error {
+	return scanJSON(value, p)
+}
+
+func (p CancellationPolicy) Validate() error {
+	if p.FreeCancelHours < 0 || p.CancelCutoffHours < 0 {
+		return errors.New("cancellation hours cannot be negative")
+	}
+	if p.LateCancelFeePct < 0 || p.LateCancelFeePct > 100 {
+		return errors.New("late_cancel_fee_pct must be between 0 and 100")
+	}
+	return nil
+}
+
+// CancellationRule records which part of the policy applied to a cancellation.
+type CancellationRule string
+
+const (
+	CancelRuleFree           CancellationRule = "free"            // Outside the late window, or only waitlisted
+	CancelRuleLateFee        CancellationRule = "late_fee"        // Inside the late window; fee charged
+	CancelRuleReplacePending CancellationRule = "cutoff_pending"  // Past the cutoff, the spot is on offer to the waitlist; the fee is held
+	CancelRuleReplaced       CancellationRule = "cutoff_replaced" // Past the cutoff, a waitlisted player took the spot
+	CancelRuleUnfilled       CancellationRule = "cutoff_unfilled" // Past the cutoff, nobody took the spot; the fee stands
+	CancelRuleOrganiser      CancellationRule = "organiser"       // Removed by the match organiser
+	CancelRuleMatchCancelled CancellationRule = "match_cancelled"
+	CancelRuleOfferDeclined  CancellationRule = "offer_declined" // Turned down a waitlist offer
+	CancelRuleOfferExpired   CancellationRule = "offer_expired"  // Did not answer a waitlist offer in time
+)
@@ -2,23 +2,140 @@ package models
 
 import "time"
 
+// CreateMatchRequest - title, game type, location, price and max players are
+// required unless TemplateID supplies them; any field sent overrides the template.
 type CreateMatchRequest struct {
-	Title          string `json:"title" binding:"required"`
-	Description    string `json:"description"`
-	GameType       string `json:"game_type"`
-	Date           string `json:"date" binding:"required"` // YYYY-MM-DD
-	Time           string `json:"time" binding:"required"` // HH:MM
-	Location       string `json:"location" binding:"required"`
-	Price          int    `json:"price" binding:"required"`
-	MaxPlayers     int    `json:"max_players" binding:"required"`
-	PositionQuotas string `json:"position_quotas"` // JSON string
-	PositionPrices string `json:"position_prices"` // JSON string
+	TemplateID     string         `json:"template_id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	GameType       string         `json:"game_type"`               // Sport code or name
+	Date           string         `json:"date" binding:"required"` // YYYY-MM-DD
+	Time           string         `json:"time" binding:"required"` // HH:MM
+	Location       string         `json:"location"`
+	Price          *int           `json:"price"`
+	MaxPlayers     int            `json:"max_players"`
+	PositionQuotas PositionQuotas `json:"position_quotas"` // Defaults to the sport's quotas
+	PositionPrices PositionPrices `json:"position_prices"`
+	RefundPolicy   RefundPolicy   `json:"refund_policy"`  // full (default), partial, credit
+	RefundPercent  *int           `json:"refund_percent"` // Required for partial
+
+	CancellationPolicy   *CancellationPolicy `json:"cancellation_policy"`    // Defaults to the club's policy
+	WaitlistOfferMinutes int                 `json:"waitlist_offer_minutes"` // Time to accept a freed spot; 0 = default
+
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`  // RFC3339; nil = open once published
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"` // RFC3339; nil = open until kick-off
+	DurationMinutes      int        `json:"duration_minutes"`       // 0 = default (2 hours)
+
+	VenueID        string `json:"venue_id"`        // Fills location and price when left out
+	CourtID        string `json:"court_id"`        // Required when the venue has several courts
+	AllowConflicts bool   `json:"allow_conflicts"` // Save even if the court is already booked
+
+	Latitude  *float64 `json:"latitude"` // Taken from the venue when one is set
+	Longitude *float64 `json:"longitude"`
+}
+
+type CreateSeriesRequest struct {
+	ClubID         string         `json:"club_id" binding:"required"`
+	Title          string         `json:"title" binding:"required"`
+	Description    string         `json:"description"`
+	GameType       string         `json:"game_type" binding:"required"`
+	Location       string         `json:"location" binding:"required"`
+	Price          float64        `json:"price"`
+	MaxPlayers     int            `json:"max_players" binding:"required"`
+	PositionQuotas PositionQuotas `json:"position_quotas"`
+	PositionPrices PositionPrices `json:"position_prices"`
+	RefundPolicy   RefundPolicy   `json:"refund_policy"`
+	RefundPercent  *int           `json:"refund_percent"`
+
+	CancellationPolicy   *CancellationPolicy `json:"cancellation_policy"`
+	WaitlistOfferMinutes int                 `json:"waitlist_offer_minutes"`
+	DurationMinutes      int                 `json:"duration_minutes"`
+
+	VenueID string `json:"venue_id"` // Every instance is booked at this court
+	CourtID string `json:"court_id"` // Required when the venue has several courts
+
+	Frequency         RecurrenceFrequency `json:"frequency" binding:"required"`  // weekly, biweekly, monthly
+	StartDate         string              `json:"start_date" binding:"required"` // YYYY-MM-DD of the first match
+	Time              string              `json:"time" binding:"required"`       // HH:MM
+	EndDate           string              `json:"end_date"`                      // YYYY-MM-DD; empty = no end
+	Exceptions        []string            `json:"exceptions"`                    // YYYY-MM-DD dates to skip
+	GenerateDaysAhead int                 `json:"generate_days_ahead"`
+}
+
+type MatchTemplateRequest struct {
+	Name           string         `json:"name" binding:"required"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	GameType       string         `json:"game_type" binding:"required"`
+	Location       string         `json:"location"`
+	Price          float64        `json:"price"`
+	MaxPlayers     int            `json:"max_players"`
+	PositionQuotas PositionQuotas `json:"position_quotas"`
+	PositionPrices PositionPrices `json:"position_prices"`
+}
+
+// CloneMatchRequest - every field is optional; the copy defaults to a draft
+// one week after the original.
+type CloneMatchRequest struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Time   string `json:"time"` // HH:MM
+	Title  string `json:"title"`
+	Status string `json:"status"` // draft (default) or published
+
+	AllowConflicts bool `json:"allow_conflicts"`
+}
+
+type VenueRequest struct {
+	Name         string       `json:"name" binding:"required"`
+	Address      string       `json:"address"`
+	Latitude     *float64     `json:"latitude"` // Leave both out for a venue without a pin
+	Longitude    *float64     `json:"longitude"`
+	DefaultPrice float64      `json:"default_price"`
+	OpeningHours OpeningHours `json:"opening_hours"` // {"mon": {"open": "08:00", "close": "23:00"}}
+}
+
+type CourtRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Capacity int    `json:"capacity"`
+}
+
+// GenerateTeamsRequest - every field is optional; the setup falls back to the
+// club's team setup.
+type GenerateTeamsRequest struct {
+	Seed *int64 `json:"seed"` // Reproduces an earlier split; random when omitted
+	TeamSetup
+}
+
+type TeamConstraintRequest struct {
+	Kind        ConstraintKind `json:"kind" binding:"required"` // lock, together or apart
+	UserID      string         `json:"user_id" binding:"required"`
+	OtherUserID string         `json:"other_user_id"` // together and apart
+	Slot        int            `json:"slot"`          // lock: 0-based team slot
+}
+
+type CreateFixturesRequest struct {
+	Format FixtureFormat `json:"format" binding:"required"` // round_robin, winner_stays_on or knockout
+	Legs   int           `json:"legs"`                      // Round robin; default 1
+	Games  int           `json:"games"`                     // Winner stays on; 0 = open-ended
+}
+
+type FixtureResultRequest struct {
+	HomeScore *int `json:"home_score" binding:"required"`
+	AwayScore *int `json:"away_score" binding:"required"`
+}
+
+type SetRatingRequest struct {
+	Rating float64 `json:"rating" binding:"required"`
+}
+
+type SubscribeSeriesRequest struct {
+	Position Position `json:"position" binding:"required"`
 }
 
 type JoinMatchRequest struct {
 	MatchID  string   `json:"match_id"`
-	Date     string   `json:"date"` // Optional if joining by date
-	Position Position `json:"position" binding:"required,oneof=gk player_front player_back defender midfielder forward"`
+	Date     string   `json:"date"`                        // Optional if joining by date
+	Position Position `json:"position" binding:"required"` // Validated against the match's sport positions
 }
 
 type RegisterRequest struct {
@@ -41,9 +158,82 @@ type UpdateUserRequest struct {
 	Avatar string `json:"avatar"`
 }
 
+type AdminCreateUserRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	Email    string   `json:"email" binding:"required,email"`
+	Password string   `json:"password" binding:"required,min=6"`
+	Phone    string   `json:"phone"`
+	Role     UserRole `json:"role" binding:"omitempty,oneof=admin player"`
+}
+
+type AdminUpdateUserRequest struct {
+	Name   string `json:"name"`
+	Email  string `json:"email" binding:"omitempty,email"`
+	Phone  string `json:"phone"`
+	Avatar string `json:"avatar"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role UserRole `json:"role" binding:"required,oneof=admin player"`
+}
+
+type UpdateUserStatusRequest struct {
+	Status         UserStatus `json:"status" binding:"required,oneof=active suspended banned"`
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"` // Only for suspended, RFC3339
+}
+
+type SportRequest struct {
+	Name string `json:"name" binding:"required"`
+	Code string `json:"code" binding:"required"`
+}
+
+type SportPositionRequest struct {
+	SportID      string `json:"sport_id" binding:"required"`
+	Code         string `json:"code" binding:"required"`
+	Name         string `json:"name" binding:"required"`
+	DefaultQuota int    `json:"default_quota" binding:"min=0"`
+	SortOrder    *int   `json:"sort_order"` // Appended to the end if omitted
+}
+
+type ReorderPositionsRequest struct {
+	PositionIDs []string `json:"position_ids" binding:"required,min=1"`
+}
+
+type SetPaymentRequest struct {
+	IsPaid     bool     `json:"is_paid"`
+	AmountPaid *float64 `json:"amount_paid"` // Optional partial payment; overrides is_paid
+	Note       string   `json:"note"`        // Kept in the override log
+}
+
+type PositionFinance struct {
+	Bookings    int     `json:"bookings"`
+	Expected    float64 `json:"expected"`
+	Collected   float64 `json:"collected"`
+	Outstanding float64 `json:"outstanding"`
+}
+
+type MatchFinancialSummary struct {
+	MatchID          string                       `json:"match_id"`
+	ConfirmedCount   int                          `json:"confirmed_count"`
+	PaidCount        int                          `json:"paid_count"`
+	ExpectedTotal    float64                      `json:"expected_total"`
+	CollectedTotal   float64                      `json:"collected_total"`
+	OutstandingTotal float64                      `json:"outstanding_total"`
+	ByPosition       map[Position]PositionFinance `json:"by_position"`
+}
+
+// Page is the envelope of every paginated list. Pass NextCursor back as
+// ?cursor= for the following page; it is empty on the last one.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+	Total      int64  `json:"total"` // Rows matching the filters, across all pages
+}
+
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token string   `json:"token"`
+	User  SelfUser `json:"user"`
 }
 
 type MatchResponse struct {
@@ -53,3 +243,80 @@ type MatchResponse struct {
 	Bookings []Booking        `json:"bookings"`
 	Counts   map[Position]int `json:"counts"`
 }
+
+// PositionCounts is one position's tally on a match list row.
+type PositionCounts struct {
+	Quota     int `json:"quota"`     // From the match's quotas; 0 when not set
+	Confirmed int `json:"confirmed"` // Includes spots held by an open waitlist offer
+	Waitlist  int `json:"waitlist"`
+}
+
+type ClubSummary struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Logo string `json:"logo"`
+}
+
+// MyBookingSummary is the caller's own booking on a listed match.
+type MyBookingSummary struct {
+	ID       string        `json:"id"`
+	Position Position      `json:"position"`
+	Status   BookingStatus `json:"status"`
+	IsPaid   bool          `json:"is_paid"`
+}
+
+// MatchSummary is a match as shown in lists: booking counts instead of the
+// bookings themselves, which only GetMatch returns.
+type MatchSummary struct {
+	ID          string       `json:"id"`
+	Title       string       `json:"title"`
+	Description string       `json:"description"`
+	GameType    string       `json:"game_type"`
+	ClubID      *string      `json:"club_id"`
+	Club        *ClubSummary `json:"club"`
+	SeriesID    *string      `json:"series_id"`
+	VenueID     *string      `json:"venue_id"`
+	CourtID     *string      `json:"court_id"`
+	Creator     PublicUser   `json:"creator"`
+	Date        time.Time    `json:"date"`
+	Location    string       `json:"location"`
+	Price       float64      `json:"price"`
+	MaxPlayers  int          `json:"max_players"`
+	Status      MatchStatus  `json:"status"`
+
+	PositionQuotas PositionQuotas `json:"position_quotas"`
+	PositionPrices PositionPrices `json:"position_prices"`
+
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"`
+	DurationMinutes      int        `json:"duration_minutes"`
+
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+	DistanceKm *float64 `json:"distance_km,omitempty"`
+
+	Counts         map[Position]PositionCounts `json:"counts"`
+	ConfirmedCount int                         `json:"confirmed_count"`
+	WaitlistCount  int                         `json:"waitlist_count"`
+	MyBooking      *MyBookingSummary           `json:"my_booking"` // nil when signed out or not booked
+}
+
+type SettleRefundRequest struct {
+	Note string `json:"note"`
+}
+
+type WalletSummary struct {
+	ClubID       string              `json:"club_id"`
+	Balance      float64             `json:"balance"`
+	Transactions []WalletTransaction `json:"transactions"`
+}
+
+// CancellationOutcome tells the client which cancellation rule applied.
+type CancellationOutcome struct {
+	Booking            *Booking           `json:"booking"`
+	Rule               CancellationRule   `json:"rule"`
+	Fee                float64            `json:"fee"`
+	HoursBeforeKickoff float64            `json:"hours_before_kickoff"`
+	Policy             CancellationPolicy `json:"policy"`
+	Refund             *Refund            `json:"refund"` // nil when nothing is refunded
+}
@@ -0,0 +1,89 @@
+package models
+
+import "time"
+
+type FixtureFormat string
+
+const (
+	FormatRoundRobin    FixtureFormat = "round_robin"     // Every team plays every other, Legs times
+	FormatWinnerStaysOn FixtureFormat = "winner_stays_on" // Winner plays the team that has waited longest
+	FormatKnockout      FixtureFormat = "knockout"        // Single elimination; top slots get the byes
+)
+
+type FixtureStatus string
+
+const (
+	FixtureScheduled FixtureStatus = "scheduled"
+	FixturePlayed    FixtureStatus = "played"
+)
+
+// Points for the standings table.
+const (
+	PointsWin  = 3
+	PointsDraw = 1
+)
+
+// MaxRoundRobinLegs bounds how many times teams meet in a round robin.
+const MaxRoundRobinLegs = 4
+
+// FixtureSchedule is the mini tournament played inside a match, between the
+// playing teams of its published lineup. A match has at most one.
+type FixtureSchedule struct {
+	ID          string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	MatchID     string        `gorm:"uniqueIndex" json:"match_id"`
+	LineupID    string        `gorm:"type:uuid" json:"lineup_id"`
+	Format      FixtureFormat `json:"format"`
+	Legs        int           `json:"legs"`  // Round robin: times each pair meets
+	Games       int           `json:"games"` // Winner stays on: games to play; 0 = until the organiser stops
+	CreatedByID string        `json:"created_by_id"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// Fixture is one game of a schedule. Knockout games past the first round,
+// and the next winner-stays-on game, only get their teams once the games
+// before them are played.
+type Fixture struct {
+	ID         string  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	MatchID    string  `gorm:"index" json:"match_id"`
+	Seq        int     `json:"seq"`   // Game number within the match, from 1
+	Round      int     `json:"round"` // Round-robin or knockout round, from 1; the game number for winner stays on
+	HomeTeamID *string `gorm:"type:uuid" json:"home_team_id"`
+	HomeTeam   *Team   `gorm:"foreignKey:HomeTeamID" json:"home_team,omitempty"`
+	AwayTeamID *string `gorm:"type:uuid" json:"away_team_id"`
+	AwayTeam   *Team   `gorm:"foreignKey:AwayTeamID" json:"away_team,omitempty"`
+
+	HomeScore    *int          `json:"home_score"`
+	AwayScore    *int          `json:"away_score"`
+	WinnerTeamID *string       `gorm:"type:uuid" json:"winner_team_id"` // nil for draws and unplayed games
+	Status       FixtureStatus `json:"status"`
+	PlayedAt     *time.Time    `json:"played_at"`
+
+	NextFixtureID *string `gorm:"type:uuid" json:"next_fixture_id"` // Knockout: the game the winner goes on to
+	NextAsHome    bool    `json:"next_as_home"`                     // Knockout: whether the winner is home there
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Standing is one team's row in the standings table.
+type Standing struct {
+	TeamID         string `json:"team_id"`
+	Name           string `json:"name"`
+	Color          string `json:"color"`
+	Played         int    `json:"played"`
+	Won            int    `json:"won"`
+	Drawn          int    `json:"drawn"`
+	Lost           int    `json:"lost"`
+	GoalsFor       int    `json:"goals_for"`
+	GoalsAgainst   int    `json:"goals_against"`
+	GoalDifference int    `json:"goal_difference"`
+	Points         int    `json:"points"`
+}
+
+// MatchResults is everything played inside a match so far.
+type MatchResults struct {
+	Schedule       *FixtureSchedule `json:"schedule"` // nil when no fixtures were set up
+	Fixtures       []Fixture        `json:"fixtures"`
+	Standings      []Standing       `json:"standings"`
+	ChampionTeamID *string          `json:"champion_team_id"` // Knockout, once the final is played
+}
@@ -0,0 +1,114 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// MatchStatus is a match's place in its lifecycle:
+//
+//	draft → published → registration_closed → in_progress → completed
+//
+// Anything before completed may be cancelled. A match with registration
+// closed can be re-opened until kick-off.
+type MatchStatus string
+
+const (
+	MatchDraft              MatchStatus = "draft"
+	MatchPublished          MatchStatus = "published" // Open for registration
+	MatchRegistrationClosed MatchStatus = "registration_closed"
+	MatchInProgress         MatchStatus = "in_progress"
+	MatchCompleted          MatchStatus = "completed"
+	MatchCancelled          MatchStatus = "cancelled"
+)
+
+// DefaultMatchDuration applies when a match doesn't set DurationMinutes.
+const DefaultMatchDuration = 2 * time.Hour
+
+var matchTransitions = map[MatchStatus][]MatchStatus{
+	MatchDraft:              {MatchPublished, MatchCancelled},
+	MatchPublished:          {MatchRegistrationClosed, MatchInProgress, MatchCancelled},
+	MatchRegistrationClosed: {MatchPublished, MatchInProgress, MatchCancelled},
+	MatchInProgress:         {MatchCompleted, MatchCancelled},
+}
+
+// ListedMatchStatuses are the statuses shown to players browsing matches or
+// their own match history.
+var ListedMatchStatuses = []MatchStatus{MatchPublished, MatchRegistrationClosed, MatchInProgress, MatchCompleted}
+
+func (s MatchStatus) IsValid() bool {
+	switch s {
+	case MatchDraft, MatchPublished, MatchRegistrationClosed, MatchInProgress, MatchCompleted, MatchCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo reports whether the lifecycle allows moving from s to next.
+func (s MatchStatus) CanTransitionTo(next MatchStatus) bool {
+	for _, allowed := range matchTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateTransition explains why moving from s to next is not allowed.
+func (s MatchStatus) ValidateTransition(next MatchStatus) error {
+	if !next.IsValid() {
+		return fmt.Errorf("unknown match status %q", next)
+	}
+	if s == next {
+		return fmt.Errorf("match is already %s", s)
+	}
+	if !s.CanTransitionTo(next) {
+		return fmt.Errorf("match cannot go from %s to %s", s, next)
+	}
+	return nil
+}
+
+// EndsAt is kick-off plus the match's duration.
+func (m Match) EndsAt() time.Time {
+	duration := DefaultMatchDuration
+	if m.DurationMinutes > 0 {
+		duration = time.Duration(m.DurationMinutes) * time.Minute
+	}
+	return m.Date.Add(duration)
+}
+
+// CheckRegistration returns why players cannot join at now, or nil when the
+// match is published and now falls inside its registration window.
+func (m Match) CheckRegistration(now time.Time) error {
+	if m.Status != MatchPublished {
+		return fmt.Errorf("registration is not open (match is %s)", m.Status)
+	}
+	if m.RegistrationOpensAt != nil && now.Before(*m.RegistrationOpensAt) {
+		return fmt.Errorf("registration opens at %s", m.RegistrationOpensAt.Format(time.RFC3339))
+	}
+	if m.RegistrationClosesAt != nil && !now.Before(*m.RegistrationClosesAt) {
+		return fmt.Errorf("registration closed at %s", m.RegistrationClosesAt.Format(time.RFC3339))
+	}
+	if !now.Before(m.Date) {
+		return errors.New("match has already started")
+	}
+	return nil
+}
+
+// ValidateSchedule checks the registration window against kick-off.
+func (m Match) ValidateSchedule() error {
+	if m.DurationMinutes < 0 {
+		return errors.New("duration_minutes cannot be negative")
+	}
+	if m.RegistrationOpensAt != nil && m.RegistrationClosesAt != nil && !m.RegistrationOpensAt.Before(*m.RegistrationClosesAt) {
+		return errors.New("registration must open before it closes")
+	}
+	if m.RegistrationClosesAt != nil && m.RegistrationClosesAt.After(m.Date) {
+		return errors.New("registration must close by kick-off")
+	}
+	if m.RegistrationOpensAt != nil && !m.RegistrationOpensAt.Before(m.Date) {
+		return errors.New("registration must open before kick-off")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+type LineupStatus string
+
+const (
+	LineupDraft      LineupStatus = "draft"      // Only the organiser sees it; members can still be moved
+	LineupPublished  LineupStatus = "published"  // What GET /matches/:id/teams returns
+	LineupSuperseded LineupStatus = "superseded" // Was published until a later version was
+)
+
+// TeamLineup is one version of a match's teams. Every generation and every
+// rollback adds a version; none is ever overwritten, so the history stays
+// complete. At most one version per match is published.
+type TeamLineup struct {
+	ID             string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	MatchID        string       `gorm:"uniqueIndex:idx_match_version" json:"match_id"`
+	Version        int          `gorm:"uniqueIndex:idx_match_version" json:"version"` // 1, 2, … per match
+	Status         LineupStatus `json:"status"`
+	Seed           *int64       `json:"seed"`             // Generated versions: the seed to reproduce the split
+	RolledBackFrom *int         `json:"rolled_back_from"` // Rollbacks: the version copied
+	Leftover       BookingIDs   `json:"leftover"`         // Waitlist strategy: bookings sent back to the waitlist on publish
+	CreatedByID    string       `json:"created_by_id"`
+	PublishedAt    *time.Time   `json:"published_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	Teams          []Team       `gorm:"foreignKey:LineupID" json:"teams,omitempty"`
+
+	// Publish and rollback: members left out because their booking is no
+	// longer confirmed and paid
+	Dropped []TeamMember `gorm:"-" json:"dropped,omitempty"`
+}
+
+// BookingIDs is a list of booking IDs. Stored as JSONB.
+type BookingIDs []string
+
+func (BookingIDs) GormDataType() string { return "jsonb" }
+
+func (ids BookingIDs) Value() (driver.Value, error) {
+	if ids == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(ids))
+	return string(b), err
+}
+
+func (ids *BookingIDs) Scan(value interface{}) error {
+	list := []string{}
+	if err := scanJSON(value, &list); err != nil {
+		return err
+	}
+	*ids = list
+	return nil
+}
+
+// LineupChange is one player whose team differs between two versions. An
+// empty team means the player isn't in that version.
+type LineupChange struct {
+	UserID   string `json:"user_id"`
+	Name     string `json:"name"`
+	FromTeam string `json:"from_team"`
+	ToTeam   string `json:"to_team"`
+}
+
+type LineupDiff struct {
+	From    int            `json:"from"`
+	To      int            `json:"to"`
+	Changes []LineupChange `json:"changes"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -11,6 +12,17 @@ const (
 	RolePlayer UserRole = "player"
 )
 
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusBanned    UserStatus = "banned"
+)
+
+// Position is a SportPosition code. The bookable positions of a match come from
+// its sport's SportPosition rows and PositionQuotas; the constants below are only
+// the codes used by the seeded sports.
 type Position string
 
 const (
@@ -27,23 +39,40 @@ type BookingStatus string
 const (
 	StatusConfirmed BookingStatus = "confirmed"
 	StatusWaitlist  BookingStatus = "waitlist"
+	StatusOffered   BookingStatus = "offered" // Waitlisted player holding a freed spot until OfferExpiresAt
 	StatusCancelled BookingStatus = "cancelled"
 )
 
 type User struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `gorm:"uniqueIndex" json:"email"`
-	Phone     string    `json:"phone"`
-	Password  string    `json:"-"` // Hidden from JSON
-	Avatar    string    `json:"avatar"`
-	Provider  string    `json:"provider"` // google, facebook, local
-	Role      UserRole  `json:"role"`
-	PushToken string    `json:"push_token"` // Expo push notification token
+	ID        string   `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	Name      string   `json:"name"`
+	Email     string   `gorm:"uniqueIndex" json:"email"`
+	Phone     string   `json:"phone"`
+	Password  string   `json:"-"` // Hidden from JSON
+	Avatar    string   `json:"avatar"`
+	Provider  string   `json:"provider"` // google, facebook, local
+	Role      UserRole `json:"role"`
+	PushToken string   `json:"-"` // Expo push notification token; never serialized
+
+	Status         UserStatus `gorm:"default:'active'" json:"status"`
+	StatusReason   string     `json:"status_reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"` // nil with status suspended = indefinite
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// IsBlocked reports whether the account is banned or currently suspended.
+func (u *User) IsBlocked(now time.Time) bool {
+	switch u.Status {
+	case UserStatusBanned:
+		return true
+	case UserStatusSuspended:
+		return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+	}
+	return false
+}
+
 type Club struct {
 	ID          string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
 	Name        string       `json:"name"`
@@ -53,9 +82,17 @@ type Club struct {
 	Creator     User         `gorm:"foreignKey:CreatorID" json:"creator"`
 	Members     []ClubMember `gorm:"foreignKey:ClubID" json:"members"`
 	SocialMedia string       `json:"social_media"` // JSON string: {"instagram": "...", "facebook": "..."}
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   time.Time    `json:"updated_at"`
-	MemberCount int          `gorm:"-" json:"member_count"` // Computed field
+
+	CancellationPolicy CancellationPolicy `json:"cancellation_policy"` // Default for the club's matches
+	TeamSetup          TeamSetup          `json:"team_setup"`          // Default for generating teams
+
+	Latitude   *float64 `json:"latitude"`
+	Longitude  *float64 `json:"longitude"`
+	DistanceKm *float64 `gorm:"->;-:migration" json:"distance_km,omitempty"` // Only set by nearby searches
+
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	MemberCount int       `gorm:"-" json:"member_count"` // Computed field
 }
 
 type Announcement struct {
@@ -99,41 +136,214 @@ type Match struct {
 	ClubID *string `gorm:"index" json:"club_id"`
 	Club   Club    `gorm:"foreignKey:ClubID" json:"club"`
 
-	CreatorID        string    `gorm:"index" json:"creator_id"`
-	Creator          User      `gorm:"foreignKey:CreatorID" json:"creator"`
-	Date             time.Time `json:"date"`
-	Location         string    `json:"location"`
-	Price            float64   `json:"price"`
-	MaxPlayers       int       `json:"max_players"`
-	Status           string    `json:"status"` // draft, published, cancelled
-	RescheduleReason string    `json:"reschedule_reason"`
-	CancelReason     string    `json:"cancel_reason"`
-	PositionQuotas   string    `json:"position_quotas"` // JSON: {"gk": 2, "player_front": 5}
-	PositionPrices   string    `json:"position_prices"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
-	Bookings         []Booking `gorm:"foreignKey:MatchID" json:"bookings"`
+	SeriesID *string `gorm:"index" json:"series_id"` // Set when generated from a MatchSeries
+
+	VenueID *string `gorm:"index" json:"venue_id"`
+	Venue   *Venue  `gorm:"foreignKey:VenueID" json:"venue,omitempty"`
+	CourtID *string `gorm:"index" json:"court_id"` // nil when the venue has no courts
+
+	// Create / update only: problems at the venue that didn't block saving
+	VenueWarnings []string `gorm:"-" json:"venue_warnings,omitempty"`
+
+	Latitude   *float64 `json:"latitude"` // From the venue when one is set
+	Longitude  *float64 `json:"longitude"`
+	DistanceKm *float64 `gorm:"->;-:migration" json:"distance_km,omitempty"` // Only set by nearby searches
+
+	CreatorID        string         `gorm:"index" json:"creator_id"`
+	Creator          User           `gorm:"foreignKey:CreatorID" json:"creator"`
+	Date             time.Time      `json:"date"`
+	Location         string         `json:"location"`
+	Price            float64        `json:"price"`
+	MaxPlayers       int            `json:"max_players"`
+	LineupCap        int            `gorm:"default:0" json:"lineup_cap"` // Spots the published lineup has room for once it left players over; 0 = no cap
+	Status           MatchStatus    `json:"status"`
+	RescheduleReason string         `json:"reschedule_reason"`
+	CancelReason     string         `json:"cancel_reason"`
+	PositionQuotas   PositionQuotas `json:"position_quotas"` // {"gk": 2, "player_front": 5}
+	PositionPrices   PositionPrices `json:"position_prices"` // {"gk": 20000}
+	RefundPolicy     RefundPolicy   `gorm:"default:'full'" json:"refund_policy"`
+	RefundPercent    int            `gorm:"default:100" json:"refund_percent"` // Share returned under the partial policy
+
+	CancellationPolicy   *CancellationPolicy `json:"cancellation_policy"`    // nil = use the club's policy
+	WaitlistOfferMinutes int                 `json:"waitlist_offer_minutes"` // 0 = default window
+
+	RegistrationOpensAt  *time.Time `json:"registration_opens_at"`  // nil = open once published
+	RegistrationClosesAt *time.Time `json:"registration_closes_at"` // nil = open until kick-off
+	DurationMinutes      int        `json:"duration_minutes"`       // 0 = DefaultMatchDuration
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Bookings  []Booking `gorm:"foreignKey:MatchID" json:"bookings"`
 }
 
 type Booking struct {
-	ID            string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
-	MatchID       string        `gorm:"index" json:"match_id"`
-	Match         Match         `gorm:"foreignKey:MatchID" json:"match"`
-	UserID        string        `gorm:"index" json:"user_id"`
-	User          User          `gorm:"foreignKey:UserID" json:"user"`
-	Position      Position      `json:"position"` // "gk", "player_front", etc.
-	Status        BookingStatus `gorm:"default:'confirmed'" json:"status"`
-	IsPaid        bool          `gorm:"default:false" json:"is_paid"`
-	WaitlistOrder int           `gorm:"default:0" json:"waitlist_order"` // 0 if confirmed, 1+ if waitlist
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
+	ID             string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	MatchID        string        `gorm:"index" json:"match_id"`
+	Match          Match         `gorm:"foreignKey:MatchID" json:"match"`
+	UserID         string        `gorm:"index" json:"user_id"`
+	User           User          `gorm:"foreignKey:UserID" json:"user"`
+	Position       Position      `json:"position"` // "gk", "player_front", etc.
+	Status         BookingStatus `gorm:"default:'confirmed'" json:"status"`
+	IsPaid         bool          `gorm:"default:false" json:"is_paid"`
+	Amount         float64       `gorm:"default:0" json:"amount"`         // Price owed, fixed at join time
+	AmountPaid     float64       `gorm:"default:0" json:"amount_paid"`    // Collected so far
+	WaitlistOrder  int           `gorm:"default:0" json:"waitlist_order"` // 0 if confirmed, 1+ if waitlist
+	OfferExpiresAt *time.Time    `json:"offer_expires_at"`                // Set while status is offered
+
+	CancelledAt      *time.Time       `json:"cancelled_at"`
+	CancelledByID    *string          `json:"cancelled_by_id"`
+	CancelReason     string           `json:"cancel_reason"`
+	CancellationRule CancellationRule `json:"cancellation_rule"`
+	CancellationFee  float64          `gorm:"default:0" json:"cancellation_fee"` // Owed instead of Amount once cancelled
+
+	History []BookingHistory `gorm:"foreignKey:BookingID" json:"history,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type BookingEvent string
+
+const (
+	EventWaitlisted     BookingEvent = "waitlisted"
+	EventOffered        BookingEvent = "offered"
+	EventOfferAccepted  BookingEvent = "offer_accepted"
+	EventOfferDeclined  BookingEvent = "offer_declined"
+	EventOfferExpired   BookingEvent = "offer_expired"
+	EventMatchCancelled BookingEvent = "match_cancelled" // Still waitlisted or offered when the match was cancelled
+)
+
+// BookingHistory records each step of a booking's way off the waitlist.
+type BookingHistory struct {
+	ID         string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	BookingID  string        `gorm:"index" json:"booking_id"`
+	Event      BookingEvent  `json:"event"`
+	FromStatus BookingStatus `json:"from_status"`
+	ToStatus   BookingStatus `json:"to_status"`
+	Note       string        `json:"note"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// Outstanding is what the player still owes for this booking. A cancelled
+// booking only owes its late-cancel fee.
+func (b Booking) Outstanding() float64 {
+	owed := b.Amount
+	if b.Status == StatusCancelled {
+		owed = b.CancellationFee
+	}
+	if b.AmountPaid >= owed {
+		return 0
+	}
+	return owed - b.AmountPaid
+}
+
+// MarshalJSON adds the computed outstanding balance.
+func (b Booking) MarshalJSON() ([]byte, error) {
+	type booking Booking // Drops methods to avoid recursion
+	return json.Marshal(struct {
+		booking
+		Outstanding float64 `json:"outstanding"`
+	}{booking(b), b.Outstanding()})
+}
+
+type PaymentStatus string
+
+const (
+	PaymentPending PaymentStatus = "pending"
+	PaymentPaid    PaymentStatus = "paid"
+	PaymentExpired PaymentStatus = "expired"
+	PaymentFailed  PaymentStatus = "failed"
+)
+
+// PaymentIntent is one online payment attempt for a booking's outstanding balance.
+type PaymentIntent struct {
+	ID          string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	BookingID   string        `gorm:"index" json:"booking_id"`
+	UserID      string        `gorm:"index" json:"user_id"`
+	Provider    string        `json:"provider"`
+	ProviderRef string        `gorm:"index" json:"provider_ref"` // Gateway invoice ID
+	Amount      float64       `json:"amount"`
+	AmountPaid  float64       `json:"amount_paid"`
+	Status      PaymentStatus `gorm:"default:'pending'" json:"status"`
+	PaymentURL  string        `json:"payment_url"`
+	ExpiresAt   time.Time     `json:"expires_at"`
+	PaidAt      *time.Time    `json:"paid_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// PaymentOverride is the audit trail of an organiser manually changing a booking's payment.
+type PaymentOverride struct {
+	ID                 string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	BookingID          string    `gorm:"index" json:"booking_id"`
+	ActorID            string    `gorm:"index" json:"actor_id"`
+	PreviousIsPaid     bool      `json:"previous_is_paid"`
+	NewIsPaid          bool      `json:"new_is_paid"`
+	PreviousAmountPaid float64   `json:"previous_amount_paid"`
+	NewAmountPaid      float64   `json:"new_amount_paid"`
+	Note               string    `json:"note"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// RefundPolicy decides what a player gets back when a paid booking is cancelled.
+type RefundPolicy string
+
+const (
+	RefundFull    RefundPolicy = "full"
+	RefundPartial RefundPolicy = "partial" // RefundPercent of what was paid
+	RefundCredit  RefundPolicy = "credit"  // Credited to the player's club wallet
+)
+
+type RefundStatus string
+
+const (
+	RefundPending    RefundStatus = "pending"
+	RefundProcessing RefundStatus = "processing" // Claimed for settlement; gateway refunds in flight
+	RefundSettled    RefundStatus = "settled"
+)
+
+// Refund is money owed back to a player for a cancelled paid booking.
+// Pending refunds are settled by the organiser; wallet credits settle immediately.
+type Refund struct {
+	ID          string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	BookingID   string       `gorm:"index:idx_refund_booking" json:"booking_id"` // More than one when paid again after a refund
+	MatchID     string       `gorm:"index" json:"match_id"`
+	UserID      string       `gorm:"index" json:"user_id"`
+	User        User         `gorm:"foreignKey:UserID" json:"user"`
+	ClubID      *string      `gorm:"index" json:"club_id"`
+	Policy      RefundPolicy `json:"policy"`
+	AmountPaid  float64      `json:"amount_paid"` // What the player had paid
+	Amount      float64      `json:"amount"`      // What is returned
+	Reason      string       `json:"reason"`
+	Status      RefundStatus `gorm:"default:'pending'" json:"status"`
+	ProviderRef string       `json:"provider_ref"` // Gateway refund IDs when settled online
+	Note        string       `json:"note"`
+	SettledByID *string      `json:"settled_by_id"`
+	SettledAt   *time.Time   `json:"settled_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// WalletTransaction is one entry in a player's club wallet; the balance is the sum.
+type WalletTransaction struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	ClubID      string    `gorm:"index" json:"club_id"`
+	UserID      string    `gorm:"index" json:"user_id"`
+	Amount      float64   `json:"amount"` // Positive = credit
+	RefundID    *string   `gorm:"index" json:"refund_id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Team struct {
 	ID        string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
 	MatchID   string       `gorm:"index" json:"match_id"`
-	Name      string       `json:"name"`  // Team A, Team B
-	Color     string       `json:"color"` // hex code or name
+	LineupID  string       `gorm:"type:uuid;index" json:"lineup_id"`
+	Name      string       `json:"name"`     // Team A, Team B
+	Color     string       `json:"color"`    // hex code or name
+	Slot      int          `json:"slot"`     // 0-based generation order; lock constraints pin players to a slot
+	Strength  float64      `json:"strength"` // Sum of the members' skill ratings when generated
+	Bench     bool         `json:"bench"`    // Holds leftover players; not a playing team
 	Members   []TeamMember `gorm:"foreignKey:TeamID" json:"members"`
 	CreatedAt time.Time    `json:"created_at"`
 	UpdatedAt time.Time    `json:"updated_at"`
@@ -145,21 +355,25 @@ type TeamMember struct {
 	UserID    string `gorm:"index" json:"user_id"`
 	User      User   `gorm:"foreignKey:UserID" json:"user"`
 	BookingID string `gorm:"index" json:"booking_id"` // Link to the booking that qualified them
+
+	Position Position `json:"position"`
+	Rating   float64  `json:"rating"` // Skill rating the team was balanced with
 }
 
 type Sport struct {
-	ID        string          `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	ID        string          `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
 	Name      string          `json:"name"`
 	Code      string          `gorm:"uniqueIndex" json:"code"`
 	Positions []SportPosition `gorm:"foreignKey:SportID" json:"positions"`
 }
 
 type SportPosition struct {
-	ID           string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	SportID      string `gorm:"index" json:"-"`
+	ID           string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	SportID      string `gorm:"index" json:"sport_id"`
 	Code         string `json:"code"`
 	Name         string `json:"name"`
 	DefaultQuota int    `json:"default_quota"`
+	SortOrder    int    `gorm:"default:0" json:"sort_order"` // Display order within the sport
 }
 
 // Deprecated: used for static response previously
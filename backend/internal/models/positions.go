@@ -0,0 +1,158 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// PositionQuotas maps a SportPosition code to the number of confirmed players
+// allowed in that position. Stored as JSONB.
+type PositionQuotas map[string]int
+
+// PositionPrices maps a SportPosition code to the price of a booking in that
+// position. Stored as JSONB.
+type PositionPrices map[string]float64
+
+func (PositionQuotas) GormDataType() string { return "jsonb" }
+func (PositionPrices) GormDataType() string { return "jsonb" }
+
+func (q PositionQuotas) Value() (driver.Value, error) {
+	if q == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]int(q))
+	return string(b), err
+}
+
+func (q *PositionQuotas) Scan(value interface{}) error {
+	m := map[string]int{}
+	if err := scanJSON(value, &m); err != nil {
+		return err
+	}
+	*q = m
+	return nil
+}
+
+func (p PositionPrices) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]float64(p))
+	return string(b), err
+}
+
+func (p *PositionPrices) Scan(value interface{}) error {
+	m := map[string]float64{}
+	if err := scanJSON(value, &m); err != nil {
+		return err
+	}
+	*p = m
+	return nil
+}
+
+func scanJSON(value interface{}, dest interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type %T for JSON column", value)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, dest)
+}
+
+// UnmarshalJSON accepts an object ({"gk": 2}) or, for older clients, the same
+// object encoded as a JSON string. Values may be numbers or numeric strings.
+func (q *PositionQuotas) UnmarshalJSON(data []byte) error {
+	raw, err := decodePositionMap(data)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		*q = nil
+		return nil
+	}
+	m := make(PositionQuotas, len(raw))
+	for code, v := range raw {
+		f, err := positionNumber(v)
+		if err != nil {
+			return fmt.Errorf("position_quotas.%s: %v", code, err)
+		}
+		if f != float64(int(f)) {
+			return fmt.Errorf("position_quotas.%s: must be a whole number", code)
+		}
+		m[code] = int(f)
+	}
+	*q = m
+	return nil
+}
+
+// UnmarshalJSON accepts the same shapes as PositionQuotas.UnmarshalJSON.
+func (p *PositionPrices) UnmarshalJSON(data []byte) error {
+	raw, err := decodePositionMap(data)
+	if err != nil {
+		return err
+	}
+	if raw == nil {
+		*p = nil
+		return nil
+	}
+	m := make(PositionPrices, len(raw))
+	for code, v := range raw {
+		f, err := positionNumber(v)
+		if err != nil {
+			return fmt.Errorf("position_prices.%s: %v", code, err)
+		}
+		m[code] = f
+	}
+	*p = m
+	return nil
+}
+
+// decodePositionMap returns nil for null or an empty string.
+func decodePositionMap(data []byte) (map[string]interface{}, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, err
+		}
+		if s == "" {
+			return nil, nil
+		}
+		data = []byte(s)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, errors.New("must be an object of position code to number")
+	}
+	return raw, nil
+}
+
+func positionNumber(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return n, nil
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		if err != nil {
+			return 0, errors.New("must be a number")
+		}
+		return f, nil
+	}
+	return 0, errors.New("must be a number")
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// PublicUser is what other people see of a user: no contact details,
+// login provider, role or push token.
+type PublicUser struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
+}
+
+// SelfUser is a user's own profile, as returned to that user and to platform
+// admins. The push token itself is never sent back.
+type SelfUser struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Email    string   `json:"email"`
+	Phone    string   `json:"phone"`
+	Avatar   string   `json:"avatar"`
+	Provider string   `json:"provider"`
+	Role     UserRole `json:"role"`
+
+	Status         UserStatus `json:"status"`
+	StatusReason   string     `json:"status_reason"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	HasPushToken   bool       `json:"has_push_token"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (u User) Public() PublicUser {
+	return PublicUser{ID: u.ID, Name: u.Name, Avatar: u.Avatar}
+}
+
+func (u User) Self() SelfUser {
+	return SelfUser{
+		ID:             u.ID,
+		Name:           u.Name,
+		Email:          u.Email,
+		Phone:          u.Phone,
+		Avatar:         u.Avatar,
+		Provider:       u.Provider,
+		Role:           u.Role,
+		Status:         u.Status,
+		StatusReason:   u.StatusReason,
+		SuspendedUntil: u.SuspendedUntil,
+		HasPushToken:   u.PushToken != "",
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+	}
+}
+
+// SelfUsers converts a list for admin responses.
+func SelfUsers(users []User) []SelfUser {
+	out := make([]SelfUser, len(users))
+	for i, u := range users {
+		out[i] = u.Self()
+	}
+	return out
+}
+
+// MarshalJSON renders a user as its public profile, so users nested in
+// matches, bookings, teams and clubs never leak private fields. Handlers
+// returning a user's own profile send Self() instead.
+func (u User) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Public())
+}
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+type RecurrenceFrequency string
+
+const (
+	RecurWeekly   RecurrenceFrequency = "weekly"
+	RecurBiweekly RecurrenceFrequency = "biweekly"
+	RecurMonthly  RecurrenceFrequency = "monthly" // Same day of the month; months without that day are skipped
+)
+
+// DefaultSeriesDaysAhead is how far ahead instances are generated when a
+// series doesn't set GenerateDaysAhead.
+const DefaultSeriesDaysAhead = 28
+
+// SeriesDates is a list of YYYY-MM-DD dates. Stored as JSONB.
+type SeriesDates []string
+
+func (SeriesDates) GormDataType() string { return "jsonb" }
+
+func (d SeriesDates) Value() (driver.Value, error) {
+	if d == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(d))
+	return string(b), err
+}
+
+func (d *SeriesDates) Scan(value interface{}) error {
+	dates := []string{}
+	if err := scanJSON(value, &dates); err != nil {
+		return err
+	}
+	*d = dates
+	return nil
+}
+
+func (d SeriesDates) Contains(date time.Time) bool {
+	day := date.Format("2006-01-02")
+	for _, skip := range d {
+		if skip == day {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchSeries is a recurring club game. It holds the match template and the
+// recurrence rule; Match rows are generated from it ahead of time.
+type MatchSeries struct {
+	ID          string  `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	ClubID      string  `gorm:"index" json:"club_id"`
+	Club        Club    `gorm:"foreignKey:ClubID" json:"club"`
+	CreatorID   string  `gorm:"index" json:"creator_id"`
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	GameType    string  `json:"game_type"`
+	Location    string  `json:"location"`
+	Price       float64 `json:"price"`
+
+	VenueID *string `gorm:"index" json:"venue_id"`
+	CourtID *string `json:"court_id"` // nil when the venue has no courts
+
+	Latitude  *float64 `json:"latitude"` // From the venue, when it has a pin
+	Longitude *float64 `json:"longitude"`
+
+	MaxPlayers           int                 `json:"max_players"`
+	PositionQuotas       PositionQuotas      `json:"position_quotas"`
+	PositionPrices       PositionPrices      `json:"position_prices"`
+	RefundPolicy         RefundPolicy        `gorm:"default:'full'" json:"refund_policy"`
+	RefundPercent        int                 `gorm:"default:100" json:"refund_percent"`
+	CancellationPolicy   *CancellationPolicy `json:"cancellation_policy"` // nil = use the club's policy
+	WaitlistOfferMinutes int                 `json:"waitlist_offer_minutes"`
+	DurationMinutes      int                 `json:"duration_minutes"`
+
+	// Recurrence rule
+	Frequency         RecurrenceFrequency `json:"frequency"`
+	StartsAt          time.Time           `json:"starts_at"` // First kick-off; later ones keep its weekday / day of month and time
+	EndDate           *time.Time          `json:"end_date"`  // Last day an instance may fall on; nil = no end
+	Exceptions        SeriesDates         `json:"exceptions"`
+	GenerateDaysAhead int                 `json:"generate_days_ahead"` // 0 = DefaultSeriesDaysAhead
+	Active            bool                `gorm:"default:true" json:"active"`
+
+	Subscriptions []SeriesSubscription `gorm:"foreignKey:SeriesID" json:"subscriptions,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SeriesSubscription auto-books a player into every instance of a series.
+type SeriesSubscription struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	SeriesID  string    `gorm:"uniqueIndex:idx_series_user" json:"series_id"`
+	UserID    string    `gorm:"uniqueIndex:idx_series_user" json:"user_id"`
+	User      User      `gorm:"foreignKey:UserID" json:"user"`
+	Position  Position  `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (s MatchSeries) Validate() error {
+	switch s.Frequency {
+	case RecurWeekly, RecurBiweekly, RecurMonthly:
+	default:
+		return fmt.Errorf("unknown frequency %q", s.Frequency)
+	}
+	if s.EndDate != nil && s.EndDate.Before(s.StartsAt.Truncate(24*time.Hour)) {
+		return errors.New("end_date is before the first match")
+	}
+	for _, d := range s.Exceptions {
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return fmt.Errorf("invalid exception date %q, use YYYY-MM-DD", d)
+		}
+	}
+	if s.GenerateDaysAhead < 0 || s.DurationMinutes < 0 || s.WaitlistOfferMinutes < 0 {
+		return errors.New("generate_days_ahead, duration_minutes and waitlist_offer_minutes cannot be negative")
+	}
+	return nil
+}
+
+// Horizon is the latest kick-off to generate at now.
+func (s MatchSeries) Horizon(now time.Time) time.Time {
+	days := DefaultSeriesDaysAhead
+	if s.GenerateDaysAhead > 0 {
+		days = s.GenerateDaysAhead
+	}
+	return now.AddDate(0, 0, days)
+}
+
+// Occurrences lists the series' kick-off times in [from, to], skipping exceptions.
+func (s MatchSeries) Occurrences(from, to time.Time) []time.Time {
+	var out []time.Time
+	for n := 0; ; n++ {
+		var t time.Time
+		switch s.Frequency {
+		case RecurWeekly:
+			t = s.StartsAt.AddDate(0, 0, 7*n)
+		case RecurBiweekly:
+			t = s.StartsAt.AddDate(0, 0, 14*n)
+		case RecurMonthly:
+			t = s.StartsAt.AddDate(0, n, 0)
+			if t.Day() != s.StartsAt.Day() {
+				continue // e.g. the 31st in a 30-day month
+			}
+		default:
+			return out
+		}
+
+		if t.After(to) {
+			break
+		}
+		if s.EndDate != nil && t.Format("2006-01-02") > s.EndDate.Format("2006-01-02") {
+			break
+		}
+		if t.Before(from) || s.Exceptions.Contains(t) {
+			continue
+		}
+		out = append(out, t)
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Skill ratings run from MinSkillRating to MaxSkillRating. Players nobody
+// has rated yet count as DefaultSkillRating.
+const (
+	MinSkillRating     = 1.0
+	MaxSkillRating     = 10.0
+	DefaultSkillRating = 5.0
+)
+
+// PlayerRating is a player's skill in one sport, as rated within a club or,
+// for matches outside a club, by one organiser. Used to balance generated
+// teams. Ratings saved before they were scoped have neither set.
+type PlayerRating struct {
+	ID          string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID      string    `gorm:"uniqueIndex:idx_rating_scope" json:"user_id"`
+	Sport       string    `gorm:"uniqueIndex:idx_rating_scope" json:"sport"` // Sport code, or the lower-cased game type for free-text sports
+	ClubID      string    `gorm:"uniqueIndex:idx_rating_scope;default:''" json:"club_id"`
+	OrganiserID string    `gorm:"uniqueIndex:idx_rating_scope;default:''" json:"organiser_id"` // Matches outside a club only
+	Rating      float64   `json:"rating"`
+	UpdatedByID string    `json:"updated_by_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RatingScope is whose ratings a match uses: its club's, or for a match
+// outside a club, its organiser's own.
+type RatingScope struct {
+	ClubID      string
+	OrganiserID string
+}
+
+func MatchRatingScope(m *Match) RatingScope {
+	if m.ClubID != nil {
+		return RatingScope{ClubID: *m.ClubID}
+	}
+	return RatingScope{OrganiserID: m.CreatorID}
+}
+
+func ValidateSkillRating(rating float64) error {
+	if rating < MinSkillRating || rating > MaxSkillRating {
+		return fmt.Errorf("rating must be between %g and %g", MinSkillRating, MaxSkillRating)
+	}
+	return nil
+}
+
+// TeamSplit is the result of generating teams for a match.
+type TeamSplit struct {
+	Lineup      TeamLineup        `json:"lineup"`       // The new draft version
+	Seed        int64             `json:"seed"`         // Send it again to get the same split
+	StrengthGap float64           `json:"strength_gap"` // Strongest minus weakest team
+	Teams       []Team            `json:"teams"`
+	Unmet       []UnmetConstraint `json:"unmet"` // Constraints that could not all be satisfied
+}
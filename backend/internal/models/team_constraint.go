@@ -0,0 +1,64 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+type ConstraintKind string
+
+const (
+	ConstraintLock     ConstraintKind = "lock"     // UserID always plays in team Slot
+	ConstraintTogether ConstraintKind = "together" // UserID and OtherUserID play in the same team
+	ConstraintApart    ConstraintKind = "apart"    // UserID and OtherUserID play in different teams
+)
+
+// TeamConstraint is an organiser's rule for a match's generated teams. Rules
+// are kept apart from the teams themselves, so they survive regeneration.
+type TeamConstraint struct {
+	ID          string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	MatchID     string         `gorm:"index" json:"match_id"`
+	Kind        ConstraintKind `json:"kind"`
+	UserID      string         `gorm:"index" json:"user_id"`
+	OtherUserID string         `json:"other_user_id"` // together and apart only
+	Slot        int            `json:"slot"`          // lock only: 0-based team, see Team.Slot
+	CreatedByID string         `json:"created_by_id"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+func (c TeamConstraint) Validate() error {
+	if c.UserID == "" {
+		return errors.New("user_id is required")
+	}
+	switch c.Kind {
+	case ConstraintLock:
+		if c.Slot < 0 || c.Slot >= MaxTeamCount {
+			return fmt.Errorf("slot must be between 0 and %d", MaxTeamCount-1)
+		}
+	case ConstraintTogether, ConstraintApart:
+		if c.OtherUserID == "" {
+			return errors.New("other_user_id is required")
+		}
+		if c.OtherUserID == c.UserID {
+			return errors.New("a player can't be paired with themselves")
+		}
+	default:
+		return fmt.Errorf("unknown constraint kind %q, use lock, together or apart", c.Kind)
+	}
+	return nil
+}
+
+// Pairs reports whether c is a together or apart rule over the same two
+// players as other, in either order.
+func (c TeamConstraint) Pairs(other TeamConstraint) bool {
+	return (c.UserID == other.UserID && c.OtherUserID == other.OtherUserID) ||
+		(c.UserID == other.OtherUserID && c.OtherUserID == other.UserID)
+}
+
+// UnmetConstraint is a rule the generator could not honour, and why.
+type UnmetConstraint struct {
+	ConstraintID string         `json:"constraint_id"`
+	Kind         ConstraintKind `json:"kind"`
+	Reason       string         `json:"reason"`
+}
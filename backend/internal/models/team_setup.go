@@ -0,0 +1,116 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// LeftoverStrategy decides what happens to players who don't fit evenly
+// into the teams.
+type LeftoverStrategy string
+
+const (
+	LeftoverUneven   LeftoverStrategy = "uneven"   // They join teams anyway, some teams end up bigger
+	LeftoverBench    LeftoverStrategy = "bench"    // They sit on a bench team and can be swapped in
+	LeftoverWaitlist LeftoverStrategy = "waitlist" // Their bookings go back to the waitlist
+)
+
+const (
+	DefaultTeamCount = 3
+	MaxTeamCount     = 16
+	BenchTeamName    = "Bench"
+	benchTeamColor   = "#9e9e9e"
+)
+
+var defaultTeamColors = []string{"#ef4444", "#3b82f6", "#10b981", "#f59e0b", "#8b5cf6", "#ec4899", "#14b8a6", "#f97316"}
+
+// TeamSetup is how teams are generated for a match. Clubs store one as the
+// default for their matches (JSONB); each generation may override any field.
+type TeamSetup struct {
+	TeamCount int              `json:"team_count"` // 0 = DefaultTeamCount
+	TeamSize  int              `json:"team_size"`  // Players per team; 0 = split everyone evenly
+	Names     []string         `json:"names"`      // Defaults to Team A, Team B, …
+	Colors    []string         `json:"colors"`     // Hex codes; defaults to a fixed palette
+	Leftover  LeftoverStrategy `json:"leftover"`   // Default uneven
+}
+
+func (TeamSetup) GormDataType() string { return "jsonb" }
+
+func (s TeamSetup) Value() (driver.Value, error) {
+	b, err := json.Marshal(s)
+	return string(b), err
+}
+
+func (s *TeamSetup) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
+
+func (s TeamSetup) Validate() error {
+	if s.TeamCount < 0 || s.TeamCount > MaxTeamCount {
+		return fmt.Errorf("team_count must be between 1 and %d", MaxTeamCount)
+	}
+	if s.TeamSize < 0 {
+		return errors.New("team_size cannot be negative")
+	}
+	switch s.Leftover {
+	case "", LeftoverUneven, LeftoverBench, LeftoverWaitlist:
+	default:
+		return fmt.Errorf("unknown leftover strategy %q, use uneven, bench or waitlist", s.Leftover)
+	}
+	return nil
+}
+
+// Merge fills the fields s leaves unset from fallback (the club's defaults).
+func (s TeamSetup) Merge(fallback TeamSetup) TeamSetup {
+	if s.TeamCount == 0 {
+		s.TeamCount = fallback.TeamCount
+	}
+	if s.TeamSize == 0 {
+		s.TeamSize = fallback.TeamSize
+	}
+	if len(s.Names) == 0 {
+		s.Names = fallback.Names
+	}
+	if len(s.Colors) == 0 {
+		s.Colors = fallback.Colors
+	}
+	if s.Leftover == "" {
+		s.Leftover = fallback.Leftover
+	}
+	return s
+}
+
+func (s TeamSetup) Count() int {
+	if s.TeamCount == 0 {
+		return DefaultTeamCount
+	}
+	return s.TeamCount
+}
+
+func (s TeamSetup) Strategy() LeftoverStrategy {
+	if s.Leftover == "" {
+		return LeftoverUneven
+	}
+	return s.Leftover
+}
+
+// TeamName is the name of team i (0-based).
+func (s TeamSetup) TeamName(i int) string {
+	if i < len(s.Names) && s.Names[i] != "" {
+		return s.Names[i]
+	}
+	return "Team " + string(rune('A'+i))
+}
+
+func (s TeamSetup) TeamColor(i int) string {
+	if i < len(s.Colors) && s.Colors[i] != "" {
+		return s.Colors[i]
+	}
+	return defaultTeamColors[i%len(defaultTeamColors)]
+}
+
+func (s TeamSetup) BenchColor() string {
+	return benchTeamColor
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// MatchTemplate is a named, per-club starting point for new matches.
+type MatchTemplate struct {
+	ID             string         `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	ClubID         string         `gorm:"uniqueIndex:idx_club_template_name" json:"club_id"`
+	Name           string         `gorm:"uniqueIndex:idx_club_template_name" json:"name"`
+	CreatorID      string         `gorm:"index" json:"creator_id"`
+	Title          string         `json:"title"`
+	Description    string         `json:"description"`
+	GameType       string         `json:"game_type"`
+	Location       string         `json:"location"`
+	Price          float64        `json:"price"`
+	MaxPlayers     int            `json:"max_players"`
+	PositionQuotas PositionQuotas `json:"position_quotas"`
+	PositionPrices PositionPrices `json:"position_prices"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
@@ -0,0 +1,151 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Venue is a place a club plays at. Matches held there book one of its
+// courts (or the whole venue when it has none).
+type Venue struct {
+	ID           string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	ClubID       string       `gorm:"index" json:"club_id"`
+	Name         string       `json:"name"`
+	Address      string       `json:"address"`
+	Latitude     *float64     `json:"latitude"` // nil when the venue has no pin
+	Longitude    *float64     `json:"longitude"`
+	DefaultPrice float64      `json:"default_price"` // Used when a match doesn't set a price
+	OpeningHours OpeningHours `json:"opening_hours"`
+	Courts       []Court      `gorm:"foreignKey:VenueID" json:"courts"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// Label is the venue as match Location text.
+func (v Venue) Label() string {
+	if v.Address == "" {
+		return v.Name
+	}
+	return v.Name + ", " + v.Address
+}
+
+// Court is one bookable field or court at a venue.
+type Court struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	VenueID   string    `gorm:"index" json:"venue_id"`
+	Name      string    `json:"name"`     // "Lapangan 2"
+	Capacity  int       `json:"capacity"` // Players the court fits; 0 = unknown
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (v Venue) Validate() error {
+	if strings.TrimSpace(v.Name) == "" {
+		return errors.New("venue name is required")
+	}
+	if (v.Latitude == nil) != (v.Longitude == nil) {
+		return errors.New("latitude and longitude must be set together")
+	}
+	if v.Latitude != nil && (*v.Latitude < -90 || *v.Latitude > 90 || *v.Longitude < -180 || *v.Longitude > 180) {
+		return errors.New("latitude must be within ±90 and longitude within ±180")
+	}
+	if v.DefaultPrice < 0 {
+		return errors.New("default_price cannot be negative")
+	}
+	return v.OpeningHours.Validate()
+}
+
+// OpeningPeriod is one day's opening time, "HH:MM" to "HH:MM" ("24:00" = midnight).
+type OpeningPeriod struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+// OpeningHours maps a weekday ("mon" … "sun") to its opening time. Days left
+// out are closed; an empty map means always open. Stored as JSONB.
+type OpeningHours map[string]OpeningPeriod
+
+var weekdayKeys = map[string]bool{"mon": true, "tue": true, "wed": true, "thu": true, "fri": true, "sat": true, "sun": true}
+
+func (OpeningHours) GormDataType() string { return "jsonb" }
+
+func (h OpeningHours) Value() (driver.Value, error) {
+	if h == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(map[string]OpeningPeriod(h))
+	return string(b), err
+}
+
+func (h *OpeningHours) Scan(value interface{}) error {
+	m := map[string]OpeningPeriod{}
+	if err := scanJSON(value, &m); err != nil {
+		return err
+	}
+	*h = m
+	return nil
+}
+
+func (h OpeningHours) Validate() error {
+	for day, period := range h {
+		if !weekdayKeys[day] {
+			return fmt.Errorf("unknown opening day %q, use mon … sun", day)
+		}
+		opens, err := clockMinutes(period.Open)
+		if err != nil {
+			return err
+		}
+		closes, err := clockMinutes(period.Close)
+		if err != nil {
+			return err
+		}
+		if opens >= closes {
+			return fmt.Errorf("%s: opening time must be before closing time", day)
+		}
+	}
+	return nil
+}
+
+// Covers reports whether the venue is open for the whole of [start, end).
+func (h OpeningHours) Covers(start, end time.Time) bool {
+	if len(h) == 0 {
+		return true
+	}
+	period, ok := h[strings.ToLower(start.Weekday().String()[:3])]
+	if !ok {
+		return false
+	}
+	opens, err1 := clockMinutes(period.Open)
+	closes, err2 := clockMinutes(period.Close)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	from := start.Hour()*60 + start.Minute()
+	to := from + int(end.Sub(start).Minutes())
+	return from >= opens && to <= closes
+}
+
+// clockMinutes parses "HH:MM" (up to "24:00") into minutes after midnight.
+func clockMinutes(clock string) (int, error) {
+	if clock == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, use HH:MM", clock)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// VenueSlotCheck is the result of checking a match's time slot at its venue.
+type VenueSlotCheck struct {
+	Conflicts []Match  `json:"conflicts"` // Matches overlapping on the same court
+	Warnings  []string `json:"warnings"`
+}
+
+func (c VenueSlotCheck) OK() bool {
+	return len(c.Conflicts) == 0 && len(c.Warnings) == 0
+}
@@ -0,0 +1,102 @@
+package payment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FakeProvider is an in-memory gateway for local development and e2e runs.
+// Invoices are "paid" by calling SimulatePayment, which produces the same
+// signed webhook a real gateway would send.
+type FakeProvider struct {
+	secret  []byte
+	baseURL string
+
+	mu      sync.Mutex
+	seq     int
+	refunds map[string]*RefundResult // By idempotency key
+}
+
+func NewFakeProvider(secret, baseURL string) *FakeProvider {
+	return &FakeProvider{
+		secret:  []byte(secret),
+		baseURL: baseURL,
+		refunds: make(map[string]*RefundResult),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateInvoice(req InvoiceRequest) (*Invoice, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.seq++
+	ref := fmt.Sprintf("fake_inv_%d_%d", time.Now().UnixNano(), p.seq)
+
+	expiresIn := req.ExpiresIn
+	if expiresIn == 0 {
+		expiresIn = 24 * time.Hour
+	}
+	return &Invoice{
+		ProviderRef: ref,
+		PaymentURL:  p.baseURL + "/api/payments/fake/" + ref + "/complete",
+		ExpiresAt:   time.Now().Add(expiresIn),
+	}, nil
+}
+
+func (p *FakeProvider) VerifyWebhookSignature(payload []byte, signature string) (*WebhookEvent, error) {
+	expected := p.Sign(payload)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (p *FakeProvider) Refund(providerRef string, amount float64, reason string, idempotencyKey string) (*RefundResult, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("refund amount must be positive")
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result, ok := p.refunds[idempotencyKey]; ok {
+		return result, nil
+	}
+	result := &RefundResult{ProviderRef: "fake_refund_" + providerRef, Amount: amount}
+	p.refunds[idempotencyKey] = result
+	return result, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of the payload, as sent in the signature header.
+func (p *FakeProvider) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SimulatePayment builds a signed "paid" webhook for an invoice.
+func (p *FakeProvider) SimulatePayment(providerRef, externalID string, amount float64) ([]byte, string, error) {
+	payload, err := json.Marshal(WebhookEvent{
+		ProviderRef: providerRef,
+		ExternalID:  externalID,
+		Status:      EventPaid,
+		Amount:      amount,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, p.Sign(payload), nil
+}
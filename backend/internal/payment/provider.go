@@ -0,0 +1,63 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Event statuses reported by a provider webhook
+const (
+	EventPaid    = "paid"
+	EventExpired = "expired"
+	EventFailed  = "failed"
+)
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+type InvoiceRequest struct {
+	ExternalID  string // Our payment intent ID, echoed back in webhooks
+	Amount      float64
+	Description string
+	PayerEmail  string
+	ExpiresIn   time.Duration
+}
+
+type Invoice struct {
+	ProviderRef string // Provider's invoice ID
+	PaymentURL  string
+	ExpiresAt   time.Time
+}
+
+type WebhookEvent struct {
+	ProviderRef string  `json:"provider_ref"`
+	ExternalID  string  `json:"external_id"`
+	Status      string  `json:"status"` // paid, expired, failed
+	Amount      float64 `json:"amount"`
+}
+
+type RefundResult struct {
+	ProviderRef string
+	Amount      float64
+}
+
+// Provider is a payment gateway that bills players for bookings.
+type Provider interface {
+	Name() string
+	CreateInvoice(req InvoiceRequest) (*Invoice, error)
+	// VerifyWebhookSignature authenticates a webhook body and decodes it.
+	VerifyWebhookSignature(payload []byte, signature string) (*WebhookEvent, error)
+	// Refund returns money for a paid invoice. Calls repeated with the same
+	// idempotency key refund once and return the first result.
+	Refund(providerRef string, amount float64, reason string, idempotencyKey string) (*RefundResult, error)
+}
+
+// NewProvider builds the provider configured by name. Only the fake gateway
+// ships in-tree; real gateways implement Provider and register here.
+func NewProvider(name, webhookSecret, baseURL string) (Provider, error) {
+	switch name {
+	case "fake":
+		return NewFakeProvider(webhookSecret, baseURL), nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", name)
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"math"
+
+	"gorm.io/gorm"
+)
+
+const earthRadiusKm = 6371.0
+
+// GeoFilter limits results to RadiusKm around a point and sorts them nearest first.
+type GeoFilter struct {
+	Lat      float64
+	Lng      float64
+	RadiusKm float64
+}
+
+// haversineSQL is the great-circle distance in km from (?, ?) to the row's
+// latitude/longitude. Placeholders: lat, lng, lat. LEAST guards acos against
+// rounding just above 1. Plain SQL, so no PostGIS needed.
+const haversineSQL = "(6371 * acos(LEAST(1, cos(radians(?)) * cos(radians(latitude)) * cos(radians(longitude) - radians(?)) + sin(radians(?)) * sin(radians(latitude)))))"
+
+// geoWithin keeps rows within the radius. A bounding box on the raw columns
+// prefilters rows cheaply before the exact distance is computed.
+func geoWithin(query *gorm.DB, near *GeoFilter) *gorm.DB {
+	query = query.Where("latitude IS NOT NULL AND longitude IS NOT NULL")
+
+	latDelta := near.RadiusKm / earthRadiusKm * 180 / math.Pi
+	query = query.Where("latitude BETWEEN ? AND ?", near.Lat-latDelta, near.Lat+latDelta)
+
+	// Longitude degrees shrink towards the poles; skip that half of the box
+	// when it would wrap the antimeridian or the circle covers a pole.
+	if cosLat := math.Cos(near.Lat * math.Pi / 180); cosLat > 0.01 {
+		lngDelta := latDelta / cosLat
+		if near.Lng-lngDelta >= -180 && near.Lng+lngDelta <= 180 {
+			query = query.Where("longitude BETWEEN ? AND ?", near.Lng-lngDelta, near.Lng+lngDelta)
+		}
+	}
+
+	return query.Where(haversineSQL+" <= ?", near.Lat, near.Lng, near.Lat, near.RadiusKm)
+}
+
+// withDistance selects the row's distance as distance_km.
+func withDistance(query *gorm.DB, table string, near *GeoFilter) *gorm.DB {
+	return query.Select(table+".*, "+haversineSQL+" AS distance_km", near.Lat, near.Lng, near.Lat)
+}
+
+// nearestFirst pages nearby results by distance.
+func nearestFirst(table string, near *GeoFilter) keyset {
+	return keyset{Sort: haversineSQL, Args: []interface{}{near.Lat, near.Lng, near.Lat}, ID: table + ".id"}
+}
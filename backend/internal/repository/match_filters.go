@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"reserve_game/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// takenSQL counts the spots held on a match (confirmed players plus open
+// waitlist offers), optionally for one position.
+const (
+	takenSQL         = "(SELECT COUNT(*) FROM bookings b WHERE b.match_id = matches.id AND b.status IN ?)"
+	takenPositionSQL = "(SELECT COUNT(*) FROM bookings b WHERE b.match_id = matches.id AND b.position = ? AND b.status IN ?)"
+
+	// sportPositionsSQL joins the match's sport positions, matched on game type
+	// the same way GetSportByGameType does.
+	sportPositionsSQL = "sport_positions sp JOIN sports s ON s.id = sp.sport_id WHERE (LOWER(s.code) = LOWER(matches.game_type) OR LOWER(s.name) = LOWER(matches.game_type))"
+
+	// Without stored quotas the sport's default quotas apply
+	noQuotasSQL = "COALESCE(matches.position_quotas, '{}'::jsonb) = '{}'::jsonb"
+
+	// quotaSQL is a position's quota. Placeholders: position, position.
+	quotaSQL = "COALESCE((matches.position_quotas ->> ?)::int, CASE WHEN " + noQuotasSQL +
+		" THEN (SELECT sp.default_quota FROM " + sportPositionsSQL + " AND sp.code = ? LIMIT 1) END, 0)"
+
+	// underCapSQL holds while the match-wide MaxPlayers cap (0 = none) has room.
+	underCapSQL = "(matches.max_players = 0 OR matches.max_players > " + takenSQL + ")"
+
+	// anyPositionOpenSQL holds when at least one position is below its quota.
+	anyPositionOpenSQL = "(EXISTS (SELECT 1 FROM jsonb_each_text(matches.position_quotas) q WHERE q.value::int > " +
+		"(SELECT COUNT(*) FROM bookings b WHERE b.match_id = matches.id AND b.position = q.key AND b.status IN ?))" +
+		" OR (" + noQuotasSQL + " AND EXISTS (SELECT 1 FROM " + sportPositionsSQL + " AND sp.default_quota > " +
+		"(SELECT COUNT(*) FROM bookings b WHERE b.match_id = matches.id AND b.position = sp.code AND b.status IN ?))))"
+
+	// kickOff is the match's wall-clock start; times are entered and stored as UTC
+	kickOffSQL = "(matches.date AT TIME ZONE 'UTC')"
+)
+
+var spotHoldingStatuses = []models.BookingStatus{models.StatusConfirmed, models.StatusOffered}
+
+// applySearchFilters adds the date, time, price and open-slot filters. Slot
+// counts are computed in SQL so no bookings have to be loaded.
+func applySearchFilters(query *gorm.DB, filter MatchFilter) *gorm.DB {
+	if filter.DateFrom != nil {
+		query = query.Where("matches.date >= ?", *filter.DateFrom)
+	}
+	if filter.DateTo != nil {
+		query = query.Where("matches.date < ?", *filter.DateTo)
+	}
+	if len(filter.Weekdays) > 0 {
+		days := make([]int, len(filter.Weekdays))
+		for i, d := range filter.Weekdays {
+			days[i] = int(d) // Sunday = 0, as in Postgres DOW
+		}
+		query = query.Where("EXTRACT(DOW FROM "+kickOffSQL+") IN ?", days)
+	}
+	if filter.TimeFrom != "" {
+		query = query.Where("to_char("+kickOffSQL+", 'HH24:MI') >= ?", filter.TimeFrom)
+	}
+	if filter.TimeTo != "" {
+		query = query.Where("to_char("+kickOffSQL+", 'HH24:MI') <= ?", filter.TimeTo)
+	}
+
+	// With a position the price is what that position costs
+	if filter.MinPrice != nil || filter.MaxPrice != nil {
+		priceSQL := "matches.price"
+		var priceArgs []interface{}
+		if filter.OpenSlotFor != "" {
+			priceSQL = "COALESCE((matches.position_prices ->> ?)::numeric, matches.price)"
+			priceArgs = []interface{}{string(filter.OpenSlotFor)}
+		}
+		if filter.MinPrice != nil {
+			query = query.Where(priceSQL+" >= ?", append(priceArgs, *filter.MinPrice)...)
+		}
+		if filter.MaxPrice != nil {
+			query = query.Where(priceSQL+" <= ?", append(priceArgs, *filter.MaxPrice)...)
+		}
+	}
+
+	if filter.OpenSlotFor != "" {
+		position := string(filter.OpenSlotFor)
+		query = query.Where(quotaSQL+" > "+takenPositionSQL, position, position, position, spotHoldingStatuses).
+			Where(underCapSQL, spotHoldingStatuses)
+	}
+	if filter.OnlyWithSpace {
+		query = query.Where(underCapSQL, spotHoldingStatuses).
+			Where(anyPositionOpenSQL, spotHoldingStatuses, spotHoldingStatuses)
+	}
+	return query
+}
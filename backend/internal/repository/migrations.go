@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reserve_game/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MigratePositionColumns converts the legacy text position_quotas and
+// position_prices columns on matches to JSONB. Empty or malformed values are
+// reset to {} (and logged) so the type change cannot fail.
+// Must run before AutoMigrate; it is a no-op once the columns are JSONB.
+func MigratePositionColumns(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Match{}) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, column := range []string{"position_quotas", "position_prices"} {
+			var dataType string
+			if err := tx.Raw("SELECT data_type FROM information_schema.columns WHERE table_name = 'matches' AND column_name = ?", column).
+				Scan(&dataType).Error; err != nil {
+				return err
+			}
+			if dataType == "" || dataType == "jsonb" {
+				continue
+			}
+
+			var rows []struct {
+				ID    string
+				Value *string
+			}
+			if err := tx.Table("matches").Select("id, " + column + " AS value").Scan(&rows).Error; err != nil {
+				return err
+			}
+
+			for _, row := range rows {
+				normalized, err := normalizePositionJSON(column, row.Value)
+				if err != nil {
+					log.Printf("[Migrate] match %s: invalid %s %q reset to {}: %v", row.ID, column, *row.Value, err)
+				}
+				if row.Value != nil && *row.Value == normalized {
+					continue
+				}
+				if err := tx.Table("matches").Where("id = ?", row.ID).Update(column, normalized).Error; err != nil {
+					return err
+				}
+			}
+
+			alter := fmt.Sprintf("ALTER TABLE matches ALTER COLUMN %s TYPE jsonb USING %s::jsonb", column, column)
+			if err := tx.Exec(alter).Error; err != nil {
+				return err
+			}
+			log.Printf("[Migrate] matches.%s converted to jsonb", column)
+		}
+		return nil
+	})
+}
+
+// MigrateRatingScope drops the old one-rating-per-user-and-sport index, so
+// the same player can be rated separately per club and organiser. Must run
+// before AutoMigrate creates the scoped index; it is a no-op afterwards.
+func MigrateRatingScope(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.PlayerRating{}) || !db.Migrator().HasIndex(&models.PlayerRating{}, "idx_user_sport") {
+		return nil
+	}
+	if err := db.Migrator().DropIndex(&models.PlayerRating{}, "idx_user_sport"); err != nil {
+		return err
+	}
+	log.Printf("[Migrate] player_ratings.idx_user_sport dropped; ratings are now scoped")
+	return nil
+}
+
+// MigrateRefundIndex drops the one-refund-per-booking index, so money paid
+// after a booking was refunded gets a refund of its own. Must run before
+// AutoMigrate creates the plain index; it is a no-op afterwards.
+func MigrateRefundIndex(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Refund{}) || !db.Migrator().HasIndex(&models.Refund{}, "idx_refunds_booking_id") {
+		return nil
+	}
+	if err := db.Migrator().DropIndex(&models.Refund{}, "idx_refunds_booking_id"); err != nil {
+		return err
+	}
+	log.Printf("[Migrate] refunds.idx_refunds_booking_id dropped; a booking can have several refunds")
+	return nil
+}
+
+// normalizePositionJSON re-encodes a legacy value through the typed model so
+// numeric strings become numbers. Returns "{}" (and the parse error) for bad input.
+func normalizePositionJSON(column string, value *string) (string, error) {
+	if value == nil || *value == "" {
+		return "{}", nil
+	}
+
+	var typed interface{}
+	if column == "position_quotas" {
+		typed = &models.PositionQuotas{}
+	} else {
+		typed = &models.PositionPrices{}
+	}
+	if err := json.Unmarshal([]byte(*value), typed); err != nil {
+		return "{}", err
+	}
+
+	b, err := json.Marshal(typed)
+	if err != nil || string(b) == "null" {
+		return "{}", err
+	}
+	return string(b), nil
+}
+
+// MigrateMatchStatuses renames the legacy match statuses: "open" becomes
+// published and "closed" becomes registration_closed (the lifecycle worker
+// moves it on from there). Safe to run on every start.
+func MigrateMatchStatuses(db *gorm.DB) error {
+	renames := map[string]models.MatchStatus{
+		"open":   models.MatchPublished,
+		"closed": models.MatchRegistrationClosed,
+	}
+	for legacy, status := range renames {
+		result := db.Model(&models.Match{}).Where("status = ?", legacy).Update("status", status)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("[Migrate] %d matches moved from %s to %s", result.RowsAffected, legacy, status)
+		}
+	}
+	return nil
+}
+
+// MigrateLegacyTeams gives teams generated before lineups were versioned a
+// lineup: each match's unversioned teams become its next version, published
+// unless the match already has a published one. Must run after AutoMigrate;
+// it is a no-op once every team has a lineup.
+func MigrateLegacyTeams(db *gorm.DB) error {
+	var matchIDs []string
+	if err := db.Model(&models.Team{}).Where("lineup_id IS NULL").Distinct().Pluck("match_id", &matchIDs).Error; err != nil {
+		return err
+	}
+
+	for _, matchID := range matchIDs {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var version int
+			if err := tx.Model(&models.TeamLineup{}).Where("match_id = ?", matchID).
+				Select("COALESCE(MAX(version), 0) + 1").Scan(&version).Error; err != nil {
+				return err
+			}
+			var published int64
+			if err := tx.Model(&models.TeamLineup{}).Where("match_id = ? AND status = ?", matchID, models.LineupPublished).
+				Count(&published).Error; err != nil {
+				return err
+			}
+
+			now := time.Now()
+			lineup := &models.TeamLineup{MatchID: matchID, Version: version, Status: models.LineupPublished, PublishedAt: &now, CreatedAt: now}
+			if published > 0 {
+				lineup.Status = models.LineupSuperseded
+			}
+			if err := tx.Omit("Teams").Create(lineup).Error; err != nil {
+				return err
+			}
+			result := tx.Model(&models.Team{}).Where("match_id = ? AND lineup_id IS NULL", matchID).Update("lineup_id", lineup.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			log.Printf("[Migrate] match %s: %d teams moved into lineup version %d (%s)", matchID, result.RowsAffected, version, lineup.Status)
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("match %s: %v", matchID, err)
+		}
+	}
+	return nil
+}
+
+// MigrateVenueCoordinates clears the (0, 0) coordinates venues without a pin
+// were saved with before coordinates were optional, along with the copies on
+// their matches. Safe to run on every start.
+func MigrateVenueCoordinates(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		unpinned := tx.Model(&models.Venue{}).Select("id").Where("latitude = 0 AND longitude = 0")
+		result := tx.Model(&models.Match{}).Where("venue_id IN (?) AND latitude = 0 AND longitude = 0", unpinned).
+			Updates(map[string]interface{}{"latitude": nil, "longitude": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("[Migrate] %d matches lost the (0, 0) coordinates of their venue", result.RowsAffected)
+		}
+		result = tx.Model(&models.Venue{}).Where("latitude = 0 AND longitude = 0").
+			Updates(map[string]interface{}{"latitude": nil, "longitude": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("[Migrate] %d venues without a pin set to no coordinates", result.RowsAffected)
+		}
+		return nil
+	})
+}
+
+// MigrateBookingAmounts fills in the price of bookings made before it was
+// fixed at join time, from their match's current prices. Bookings already
+// marked paid are taken as paid in full. The organiser's own booking is free
+// and left alone. Safe to run on every start.
+func MigrateBookingAmounts(db *gorm.DB) error {
+	var bookings []models.Booking
+	err := db.Joins("JOIN matches ON matches.id = bookings.match_id").
+		Where("bookings.amount = 0 AND bookings.user_id <> matches.creator_id").
+		Find(&bookings).Error
+	if err != nil {
+		return err
+	}
+
+	matches := make(map[string]*models.Match)
+	migrated := 0
+	for _, b := range bookings {
+		match, ok := matches[b.MatchID]
+		if !ok {
+			match = &models.Match{}
+			if err := db.First(match, "id = ?", b.MatchID).Error; err != nil {
+				return fmt.Errorf("booking %s: %v", b.ID, err)
+			}
+			matches[b.MatchID] = match
+		}
+		amount := match.Price
+		if price, ok := match.PositionPrices[string(b.Position)]; ok {
+			amount = price
+		}
+		if amount == 0 {
+			continue
+		}
+
+		updates := map[string]interface{}{"amount": amount}
+		if b.IsPaid && b.AmountPaid == 0 {
+			updates["amount_paid"] = amount
+		}
+		if err := db.Model(&models.Booking{}).Where("id = ?", b.ID).Updates(updates).Error; err != nil {
+			return fmt.Errorf("booking %s: %v", b.ID, err)
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		log.Printf("[Migrate] %d bookings given the price of their position", migrated)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reserve_game/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Page sizes for every paginated list; larger limits are capped.
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageQuery asks for one page of a list. Cursor is the previous page's
+// NextCursor, empty for the first page.
+type PageQuery struct {
+	Cursor string
+	Limit  int
+}
+
+func (p PageQuery) limit() int {
+	if p.Limit < 1 {
+		return DefaultPageLimit
+	}
+	if p.Limit > MaxPageLimit {
+		return MaxPageLimit
+	}
+	return p.Limit
+}
+
+// pageCursor is the sort key of the last row on a page: a timestamp, or a
+// distance for nearby searches, with the row ID breaking ties. Rows inserted
+// meanwhile therefore never shift later pages the way offsets do.
+type pageCursor struct {
+	Time *time.Time `json:"t,omitempty"`
+	Num  *float64   `json:"n,omitempty"`
+	ID   string     `json:"id"`
+}
+
+func timeCursor(t time.Time, id string) pageCursor {
+	return pageCursor{Time: &t, ID: id}
+}
+
+func distanceCursor(km *float64, id string) pageCursor {
+	return pageCursor{Num: km, ID: id}
+}
+
+func (c pageCursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	if s == "" {
+		return nil, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" || (c.Time == nil) == (c.Num == nil) {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// keyset is a list's sort order: Sort, then the row ID to break ties, both
+// ascending or both descending.
+type keyset struct {
+	Sort string        // SQL expression, e.g. "matches.date"
+	Args []interface{} // Placeholders in Sort
+	ID   string        // e.g. "matches.id"
+	Desc bool
+}
+
+func (k keyset) order(query *gorm.DB) *gorm.DB {
+	dir := " ASC"
+	if k.Desc {
+		dir = " DESC"
+	}
+	return query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                k.Sort + dir + ", " + k.ID + dir,
+		Vars:               k.Args,
+		WithoutParentheses: true,
+	}})
+}
+
+// newestFirst pages a table by creation time, latest first.
+func newestFirst(table string) keyset {
+	return keyset{Sort: table + ".created_at", ID: table + ".id", Desc: true}
+}
+
+// after keeps the rows sorting after the cursor.
+func (k keyset) after(query *gorm.DB, c *pageCursor) *gorm.DB {
+	if c == nil {
+		return query
+	}
+	op := " > "
+	if k.Desc {
+		op = " < "
+	}
+	var value interface{}
+	if c.Time != nil {
+		value = *c.Time
+	} else {
+		value = *c.Num
+	}
+	args := append(append([]interface{}{}, k.Args...), value, c.ID)
+	return query.Where("("+k.Sort+", "+k.ID+")"+op+"(?, ?)", args...)
+}
+
+// findPage counts the rows matched by base, then loads one page of them in
+// keyset order. rows adds what only the item query needs (preloads, computed
+// columns); cursorOf gives an item's sort key.
+func findPage[T any](base *gorm.DB, page PageQuery, key keyset, rows func(*gorm.DB) *gorm.DB, cursorOf func(*T) pageCursor) (*models.Page[T], error) {
+	after, err := decodeCursor(page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.Page[T]{Items: []T{}}
+	if err := base.Session(&gorm.Session{}).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+
+	query := base.Session(&gorm.Session{})
+	if rows != nil {
+		query = rows(query)
+	}
+	query = key.order(key.after(query, after))
+
+	limit := page.limit()
+	if err := query.Limit(limit + 1).Find(&result.Items).Error; err != nil {
+		return nil, err
+	}
+	if len(result.Items) > limit {
+		result.Items = result.Items[:limit]
+		result.NextCursor = cursorOf(&result.Items[limit-1]).encode()
+	}
+	return result, nil
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"fmt"
 	"reserve_game/internal/models"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -10,21 +11,37 @@ import (
 )
 
 type MatchFilter struct {
-	Page         int
-	Limit        int
 	CreatorID    string
 	JoinedUserID string
 	Search       string
-	ClubID       string // Filter by Club
-	Status       string // draft, published, cancelled, or closed
-	GameType     string // Sport type
+	ClubID       string               // Filter by Club
+	Status       string               // A single models.MatchStatus, or "all"
+	Statuses     []models.MatchStatus // Any of these; used when Status is empty
+	GameType     string               // Sport type
+	Near         *GeoFilter           // Within a radius, nearest first
+
+	DateFrom      *time.Time     // Kick-off at or after; replaces the default "from today"
+	DateTo        *time.Time     // Kick-off before
+	Weekdays      []time.Weekday // Kick-off on any of these days
+	TimeFrom      string         // Kick-off time of day, HH:MM (UTC), inclusive
+	TimeTo        string         // Inclusive
+	MinPrice      *float64       // Price of OpenSlotFor's position when set, else the match price
+	MaxPrice      *float64
+	OpenSlotFor   models.Position // A spot in this position is still free
+	OnlyWithSpace bool            // Any spot is still free
+}
+
+type UserFilter struct {
+	Search string // name, email or phone
+	Role   string
+	Status string
 }
 
 type Repository interface {
 	CreateUser(user *models.User) error
 	GetUserByEmail(email string) (*models.User, error)
 	GetUserByID(id string) (*models.User, error)
-	GetAllUsers() ([]models.User, error)
+	ListUsers(filter UserFilter, page PageQuery) (*models.Page[models.User], error)
 	UpdateUser(user *models.User) error
 	DeleteUser(id string) error
 
@@ -37,23 +54,68 @@ type Repository interface {
 	CreateBooking(booking *models.Booking) error
 	GetBookingsByMatchID(matchID string) ([]models.Booking, error)
 	UpdateBooking(booking *models.Booking) error
-	ListMatches(filter MatchFilter) ([]models.Match, error)
+	ListMatches(filter MatchFilter, page PageQuery) (*models.Page[models.Match], error)
+	CountMatchBookings(matchIDs []string) ([]BookingCount, error)
+	GetUserMatchBookings(userID string, matchIDs []string) ([]models.Booking, error)
+	GetMatchIDsDueForAdvance(now time.Time) ([]string, error)
 	GetBookingByID(id string) (*models.Booking, error)
+	GetBookingByIDLock(id string) (*models.Booking, error) // For updating payments
 	GetWaitlist(matchID string, position models.Position) ([]models.Booking, error)
+	GetMatchWaitlist(matchID string) ([]models.Booking, error)
+	GetExpiredOfferMatchIDs(now time.Time) ([]string, error)
+	CreateBookingHistory(entry *models.BookingHistory) error
+	GetBookingHistory(bookingID string) ([]models.BookingHistory, error)
 	GetTeamsByMatchID(matchID string) ([]models.Team, error)
 	CreateTeam(team *models.Team) error
 	CreateTeamMember(member *models.TeamMember) error
 	GetTeamByID(id string) (*models.Team, error)
 	GetTeamMemberByID(id string) (*models.TeamMember, error)
-	DeleteTeamsByMatchID(matchID string) error
 	RunTransaction(fn func(repo Repository) error) error
 	FixData() error
 	UpdateTeamMember(memberID string, newTeamID string) error
+	UpdateTeamStrength(teamID string, strength float64) error
+	DeleteTeamMember(id string) error
 	GetMasterSports() ([]models.Sport, error)
+	GetPlayerRatings(scope models.RatingScope, sport string, userIDs []string) ([]models.PlayerRating, error)
+	SavePlayerRating(rating *models.PlayerRating) error
+	GetTeamConstraints(matchID string) ([]models.TeamConstraint, error)
+	NextLineupVersion(matchID string) (int, error)
+	CreateLineup(lineup *models.TeamLineup) error
+	UpdateLineup(lineup *models.TeamLineup) error
+	GetLineups(matchID string) ([]models.TeamLineup, error)
+	GetLineup(matchID string, version int) (*models.TeamLineup, error)
+	GetLineupByID(id string) (*models.TeamLineup, error)
+	SupersedeLineups(matchID string) error
+	GetTeamsByLineupID(lineupID string) ([]models.Team, error)
+	GetFixtureSchedule(matchID string) (*models.FixtureSchedule, error)
+	CreateFixtureSchedule(schedule *models.FixtureSchedule) error
+	DeleteFixtures(matchID string) error
+	GetFixtures(matchID string) ([]models.Fixture, error)
+	CreateFixture(fixture *models.Fixture) error
+	UpdateFixture(fixture *models.Fixture) error
+	DeleteFixture(id string) error
+	CreateTeamConstraint(constraint *models.TeamConstraint) error
+	DeleteTeamConstraint(id string) error
+
+	// Sport Master Data
+	CreateSport(sport *models.Sport) error
+	GetSportByID(id string) (*models.Sport, error)
+	GetSportByCode(code string) (*models.Sport, error)
+	GetSportByGameType(gameType string) (*models.Sport, error)
+	UpdateSport(sport *models.Sport) error
+	DeleteSport(id string) error
+	CreateSportPosition(position *models.SportPosition) error
+	GetSportPositionByID(id string) (*models.SportPosition, error)
+	UpdateSportPosition(position *models.SportPosition) error
+	DeleteSportPosition(id string) error
+	CountSportUsage(gameTypes ...string) (SportUsage, error)
+	CountPositionUsage(position string, gameTypes ...string) (SportUsage, error)
+	RenameGameType(oldGameType, newGameType string) error
+	RenameRatingSport(oldSport, newSport string) error
 
 	// Club Methods
 	CreateClub(club *models.Club) error
-	GetClubs(page, limit int, search string, userID string, filterType string) ([]models.Club, error)
+	GetClubs(search string, userID string, filterType string, near *GeoFilter, page PageQuery) (*models.Page[models.Club], error)
 	GetClubByID(id string) (*models.Club, error)
 	JoinClub(member *models.ClubMember) error
 	LeaveClub(userID, clubID string) error
@@ -65,15 +127,68 @@ type Repository interface {
 
 	// Announcement Methods
 	CreateAnnouncement(announcement *models.Announcement) error
-	GetClubAnnouncements(clubID string) ([]models.Announcement, error)
-	GetPublishedClubAnnouncements(clubID string) ([]models.Announcement, error)
+	GetClubAnnouncements(clubID string, page PageQuery) (*models.Page[models.Announcement], error)
+	GetPublishedClubAnnouncements(clubID string, page PageQuery) (*models.Page[models.Announcement], error)
 	GetAnnouncementByID(id string) (*models.Announcement, error)
 	UpdateAnnouncement(announcement *models.Announcement) error
 	DeleteAnnouncement(id string) error
 
+	// Payment Methods
+	CreatePaymentIntent(intent *models.PaymentIntent) error
+	UpdatePaymentIntent(intent *models.PaymentIntent) error
+	GetPaymentIntentByIDLock(id string) (*models.PaymentIntent, error)
+	GetPaymentIntentByProviderRef(ref string) (*models.PaymentIntent, error)
+	GetPaymentIntentsByBookingID(bookingID string) ([]models.PaymentIntent, error)
+	CreatePaymentOverride(override *models.PaymentOverride) error
+	GetPaymentOverridesByBookingID(bookingID string) ([]models.PaymentOverride, error)
+
+	// Refund Methods
+	CreateRefund(refund *models.Refund) error
+	UpdateRefund(refund *models.Refund) error
+	GetRefundByID(id string) (*models.Refund, error)
+	GetRefundByIDLock(id string) (*models.Refund, error)
+	GetRefundsByBookingID(bookingID string) ([]models.Refund, error)
+	GetRefundsByMatchID(matchID string, status models.RefundStatus) ([]models.Refund, error)
+	CreateWalletTransaction(tx *models.WalletTransaction) error
+	GetWalletTransactions(clubID, userID string) ([]models.WalletTransaction, error)
+
+	// Venue Methods
+	CreateVenue(venue *models.Venue) error
+	GetVenueByID(id string) (*models.Venue, error)
+	GetClubVenues(clubID string) ([]models.Venue, error)
+	UpdateVenue(venue *models.Venue) error
+	DeleteVenue(id string) error
+	CreateCourt(court *models.Court) error
+	GetCourtByID(id string) (*models.Court, error)
+	DeleteCourt(id string) error
+	CountVenueMatches(venueID string, courtID string) (int64, error)
+	CountVenueSeries(venueID string, courtID string) (int64, error)
+	FindSlotConflicts(venueID string, courtID *string, start, end time.Time, excludeMatchID string) ([]models.Match, error)
+
+	// Match Template Methods
+	CreateMatchTemplate(tmpl *models.MatchTemplate) error
+	GetMatchTemplateByID(id string) (*models.MatchTemplate, error)
+	GetClubMatchTemplates(clubID string) ([]models.MatchTemplate, error)
+	UpdateMatchTemplate(tmpl *models.MatchTemplate) error
+	DeleteMatchTemplate(id string) error
+
+	// Match Series Methods
+	CreateSeries(series *models.MatchSeries) error
+	GetSeriesByID(id string) (*models.MatchSeries, error)
+	GetSeriesByIDLock(id string) (*models.MatchSeries, error)
+	UpdateSeries(series *models.MatchSeries) error
+	GetClubSeries(clubID string) ([]models.MatchSeries, error)
+	GetActiveSeriesIDs() ([]string, error)
+	GetSeriesMatches(seriesID string, from time.Time) ([]models.Match, error)
+	CountActiveBookings(matchID string) (int64, error)
+	CreateSeriesSubscription(sub *models.SeriesSubscription) error
+	GetSeriesSubscription(seriesID, userID string) (*models.SeriesSubscription, error)
+	GetSeriesSubscriptions(seriesID string) ([]models.SeriesSubscription, error)
+	DeleteSeriesSubscription(id string) error
+
 	// Notification Methods
 	CreateNotification(notification *models.Notification) error
-	GetUserNotifications(userID string) ([]models.Notification, error)
+	GetUserNotifications(userID string, page PageQuery) (*models.Page[models.Notification], error)
 	MarkNotificationAsRead(id string) error
 
 	// User Methods
@@ -92,9 +207,13 @@ func (r *repository) CreateClub(club *models.Club) error {
 	return r.db.Create(club).Error
 }
 
-func (r *repository) GetClubs(page, limit int, search string, userID string, filterType string) ([]models.Club, error) {
-	var clubs []models.Club
-	query := r.db.Preload("Creator").Order("created_at DESC")
+func (r *repository) GetClubs(search string, userID string, filterType string, near *GeoFilter, page PageQuery) (*models.Page[models.Club], error) {
+	query := r.db.Model(&models.Club{})
+	key := keyset{Sort: "clubs.created_at", ID: "clubs.id", Desc: true}
+	if near != nil {
+		query = geoWithin(query, near)
+		key = nearestFirst("clubs", near)
+	}
 
 	if search != "" {
 		searchPattern := "%" + search + "%"
@@ -109,9 +228,18 @@ func (r *repository) GetClubs(page, limit int, search string, userID string, fil
 		query = query.Where("creator_id = ?", userID)
 	}
 
-	offset := (page - 1) * limit
-	err := query.Limit(limit).Offset(offset).Find(&clubs).Error
-	return clubs, err
+	return findPage(query, page, key, func(q *gorm.DB) *gorm.DB {
+		q = q.Preload("Creator")
+		if near != nil {
+			q = withDistance(q, "clubs", near)
+		}
+		return q
+	}, func(c *models.Club) pageCursor {
+		if near != nil {
+			return distanceCursor(c.DistanceKm, c.ID)
+		}
+		return timeCursor(c.CreatedAt, c.ID)
+	})
 }
 
 func (r *repository) JoinClub(member *models.ClubMember) error {
@@ -161,16 +289,18 @@ func (r *repository) CreateAnnouncement(announcement *models.Announcement) error
 	return r.db.Create(announcement).Error
 }
 
-func (r *repository) GetClubAnnouncements(clubID string) ([]models.Announcement, error) {
-	var announcements []models.Announcement
-	err := r.db.Where("club_id = ?", clubID).Order("created_at DESC").Find(&announcements).Error
-	return announcements, err
+func (r *repository) GetClubAnnouncements(clubID string, page PageQuery) (*models.Page[models.Announcement], error) {
+	query := r.db.Model(&models.Announcement{}).Where("club_id = ?", clubID)
+	return findPage(query, page, newestFirst("announcements"), nil, announcementCursor)
+}
+
+func (r *repository) GetPublishedClubAnnouncements(clubID string, page PageQuery) (*models.Page[models.Announcement], error) {
+	query := r.db.Model(&models.Announcement{}).Where("club_id = ? AND status = ?", clubID, "published")
+	return findPage(query, page, newestFirst("announcements"), nil, announcementCursor)
 }
 
-func (r *repository) GetPublishedClubAnnouncements(clubID string) ([]models.Announcement, error) {
-	var announcements []models.Announcement
-	err := r.db.Where("club_id = ? AND status = ?", clubID, "published").Order("created_at DESC").Find(&announcements).Error
-	return announcements, err
+func announcementCursor(a *models.Announcement) pageCursor {
+	return timeCursor(a.CreatedAt, a.ID)
 }
 
 func (r *repository) GetAnnouncementByID(id string) (*models.Announcement, error) {
@@ -197,10 +327,11 @@ func (r *repository) CreateNotification(notification *models.Notification) error
 	return r.db.Create(notification).Error
 }
 
-func (r *repository) GetUserNotifications(userID string) ([]models.Notification, error) {
-	var notifications []models.Notification
-	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&notifications).Error
-	return notifications, err
+func (r *repository) GetUserNotifications(userID string, page PageQuery) (*models.Page[models.Notification], error) {
+	query := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	return findPage(query, page, newestFirst("notifications"), nil, func(n *models.Notification) pageCursor {
+		return timeCursor(n.CreatedAt, n.ID)
+	})
 }
 
 func (r *repository) MarkNotificationAsRead(id string) error {
@@ -211,18 +342,434 @@ func (r *repository) UpdatePushToken(userID, token string) error {
 	return r.db.Model(&models.User{}).Where("id = ?", userID).Update("push_token", token).Error
 }
 
+func (r *repository) CreatePaymentIntent(intent *models.PaymentIntent) error {
+	return r.db.Create(intent).Error
+}
+
+func (r *repository) UpdatePaymentIntent(intent *models.PaymentIntent) error {
+	return r.db.Save(intent).Error
+}
+
+func (r *repository) GetPaymentIntentByIDLock(id string) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&intent, "id = ?", id).Error
+	return &intent, err
+}
+
+func (r *repository) GetPaymentIntentByProviderRef(ref string) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := r.db.Where("provider_ref = ?", ref).First(&intent).Error
+	return &intent, err
+}
+
+func (r *repository) GetPaymentIntentsByBookingID(bookingID string) ([]models.PaymentIntent, error) {
+	var intents []models.PaymentIntent
+	err := r.db.Where("booking_id = ?", bookingID).Order("created_at DESC").Find(&intents).Error
+	return intents, err
+}
+
+func (r *repository) CreatePaymentOverride(override *models.PaymentOverride) error {
+	return r.db.Create(override).Error
+}
+
+func (r *repository) GetPaymentOverridesByBookingID(bookingID string) ([]models.PaymentOverride, error) {
+	var overrides []models.PaymentOverride
+	err := r.db.Where("booking_id = ?", bookingID).Order("created_at DESC").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *repository) CreateRefund(refund *models.Refund) error {
+	return r.db.Create(refund).Error
+}
+
+func (r *repository) UpdateRefund(refund *models.Refund) error {
+	return r.db.Save(refund).Error
+}
+
+func (r *repository) GetRefundByID(id string) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.First(&refund, "id = ?", id).Error
+	return &refund, err
+}
+
+func (r *repository) GetRefundByIDLock(id string) (*models.Refund, error) {
+	var refund models.Refund
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&refund, "id = ?", id).Error
+	return &refund, err
+}
+
+func (r *repository) GetRefundsByBookingID(bookingID string) ([]models.Refund, error) {
+	var refunds []models.Refund
+	err := r.db.Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&refunds).Error
+	return refunds, err
+}
+
+// GetRefundsByMatchID - empty status returns all refunds for the match
+func (r *repository) GetRefundsByMatchID(matchID string, status models.RefundStatus) ([]models.Refund, error) {
+	var refunds []models.Refund
+	query := r.db.Preload("User").Where("match_id = ?", matchID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at ASC").Find(&refunds).Error
+	return refunds, err
+}
+
+func (r *repository) CreateVenue(venue *models.Venue) error {
+	return r.db.Create(venue).Error
+}
+
+func (r *repository) GetVenueByID(id string) (*models.Venue, error) {
+	var venue models.Venue
+	err := r.db.Preload("Courts", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).First(&venue, "id = ?", id).Error
+	return &venue, err
+}
+
+func (r *repository) GetClubVenues(clubID string) ([]models.Venue, error) {
+	var venues []models.Venue
+	err := r.db.Preload("Courts").Where("club_id = ?", clubID).Order("name ASC").Find(&venues).Error
+	return venues, err
+}
+
+func (r *repository) UpdateVenue(venue *models.Venue) error {
+	return r.db.Omit("Courts").Save(venue).Error
+}
+
+func (r *repository) DeleteVenue(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Court{}, "venue_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Venue{}, "id = ?", id).Error
+	})
+}
+
+func (r *repository) CreateCourt(court *models.Court) error {
+	return r.db.Create(court).Error
+}
+
+func (r *repository) GetCourtByID(id string) (*models.Court, error) {
+	var court models.Court
+	err := r.db.First(&court, "id = ?", id).Error
+	return &court, err
+}
+
+func (r *repository) DeleteCourt(id string) error {
+	return r.db.Delete(&models.Court{}, "id = ?", id).Error
+}
+
+// CountVenueMatches - matches (any status) held at the venue, or at one court when courtID is set
+func (r *repository) CountVenueMatches(venueID string, courtID string) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.Match{}).Where("venue_id = ?", venueID)
+	if courtID != "" {
+		query = query.Where("court_id = ?", courtID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// CountVenueSeries - active series generating matches at the venue, or at one court when courtID is set
+func (r *repository) CountVenueSeries(venueID string, courtID string) (int64, error) {
+	var count int64
+	query := r.db.Model(&models.MatchSeries{}).Where("venue_id = ? AND active = ?", venueID, true)
+	if courtID != "" {
+		query = query.Where("court_id = ?", courtID)
+	}
+	err := query.Count(&count).Error
+	return count, err
+}
+
+// FindSlotConflicts - non-cancelled matches on the same court (or the whole
+// venue when courtID is nil) whose time slot overlaps [start, end)
+func (r *repository) FindSlotConflicts(venueID string, courtID *string, start, end time.Time, excludeMatchID string) ([]models.Match, error) {
+	var matches []models.Match
+	defaultMinutes := int(models.DefaultMatchDuration.Minutes())
+	query := r.db.Where("venue_id = ? AND status <> ?", venueID, models.MatchCancelled).
+		Where("date < ?", end).
+		Where("date + make_interval(mins => CASE WHEN duration_minutes > 0 THEN duration_minutes ELSE ? END) > ?", defaultMinutes, start)
+	if courtID != nil {
+		query = query.Where("court_id = ?", *courtID)
+	} else {
+		query = query.Where("court_id IS NULL")
+	}
+	if excludeMatchID != "" {
+		query = query.Where("id <> ?", excludeMatchID)
+	}
+	err := query.Order("date ASC").Find(&matches).Error
+	return matches, err
+}
+
+func (r *repository) CreateMatchTemplate(tmpl *models.MatchTemplate) error {
+	return r.db.Create(tmpl).Error
+}
+
+func (r *repository) GetMatchTemplateByID(id string) (*models.MatchTemplate, error) {
+	var tmpl models.MatchTemplate
+	err := r.db.First(&tmpl, "id = ?", id).Error
+	return &tmpl, err
+}
+
+func (r *repository) GetClubMatchTemplates(clubID string) ([]models.MatchTemplate, error) {
+	var templates []models.MatchTemplate
+	err := r.db.Where("club_id = ?", clubID).Order("name ASC").Find(&templates).Error
+	return templates, err
+}
+
+func (r *repository) UpdateMatchTemplate(tmpl *models.MatchTemplate) error {
+	return r.db.Save(tmpl).Error
+}
+
+func (r *repository) DeleteMatchTemplate(id string) error {
+	return r.db.Delete(&models.MatchTemplate{}, "id = ?", id).Error
+}
+
+func (r *repository) CreateSeries(series *models.MatchSeries) error {
+	return r.db.Create(series).Error
+}
+
+func (r *repository) GetSeriesByID(id string) (*models.MatchSeries, error) {
+	var series models.MatchSeries
+	err := r.db.Preload("Subscriptions.User").First(&series, "id = ?", id).Error
+	return &series, err
+}
+
+func (r *repository) GetSeriesByIDLock(id string) (*models.MatchSeries, error) {
+	var series models.MatchSeries
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&series, "id = ?", id).Error
+	return &series, err
+}
+
+func (r *repository) UpdateSeries(series *models.MatchSeries) error {
+	return r.db.Omit("Subscriptions", "Club").Save(series).Error
+}
+
+func (r *repository) GetClubSeries(clubID string) ([]models.MatchSeries, error) {
+	var series []models.MatchSeries
+	err := r.db.Where("club_id = ?", clubID).Order("created_at DESC").Find(&series).Error
+	return series, err
+}
+
+func (r *repository) GetActiveSeriesIDs() ([]string, error) {
+	var ids []string
+	err := r.db.Model(&models.MatchSeries{}).Where("active = ?", true).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetSeriesMatches - the series' generated matches (any status) kicking off at or after from
+func (r *repository) GetSeriesMatches(seriesID string, from time.Time) ([]models.Match, error) {
+	var matches []models.Match
+	err := r.db.Where("series_id = ? AND date >= ?", seriesID, from).Order("date ASC").Find(&matches).Error
+	return matches, err
+}
+
+// CountActiveBookings - bookings that are not cancelled
+func (r *repository) CountActiveBookings(matchID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Booking{}).Where("match_id = ? AND status <> ?", matchID, models.StatusCancelled).Count(&count).Error
+	return count, err
+}
+
+func (r *repository) CreateSeriesSubscription(sub *models.SeriesSubscription) error {
+	return r.db.Create(sub).Error
+}
+
+func (r *repository) GetSeriesSubscription(seriesID, userID string) (*models.SeriesSubscription, error) {
+	var sub models.SeriesSubscription
+	err := r.db.First(&sub, "series_id = ? AND user_id = ?", seriesID, userID).Error
+	return &sub, err
+}
+
+func (r *repository) GetSeriesSubscriptions(seriesID string) ([]models.SeriesSubscription, error) {
+	var subs []models.SeriesSubscription
+	err := r.db.Where("series_id = ?", seriesID).Order("created_at ASC").Find(&subs).Error
+	return subs, err
+}
+
+func (r *repository) DeleteSeriesSubscription(id string) error {
+	return r.db.Delete(&models.SeriesSubscription{}, "id = ?", id).Error
+}
+
+func (r *repository) CreateWalletTransaction(tx *models.WalletTransaction) error {
+	return r.db.Create(tx).Error
+}
+
+func (r *repository) GetWalletTransactions(clubID, userID string) ([]models.WalletTransaction, error) {
+	var txs []models.WalletTransaction
+	err := r.db.Where("club_id = ? AND user_id = ?", clubID, userID).Order("created_at DESC").Find(&txs).Error
+	return txs, err
+}
+
 func (r *repository) GetClubMemberCount(clubID string) (int64, error) {
 	var count int64
 	err := r.db.Model(&models.ClubMember{}).Where("club_id = ?", clubID).Count(&count).Error
 	return count, err
 }
 
+func orderedPositions(db *gorm.DB) *gorm.DB {
+	return db.Order("sort_order ASC, name ASC")
+}
+
 func (r *repository) GetMasterSports() ([]models.Sport, error) {
 	var sports []models.Sport
-	err := r.db.Preload("Positions").Find(&sports).Error
+	err := r.db.Preload("Positions", orderedPositions).Order("name ASC").Find(&sports).Error
 	return sports, err
 }
 
+func (r *repository) CreateSport(sport *models.Sport) error {
+	return r.db.Create(sport).Error
+}
+
+func (r *repository) GetSportByID(id string) (*models.Sport, error) {
+	var sport models.Sport
+	err := r.db.Preload("Positions", orderedPositions).First(&sport, "id = ?", id).Error
+	return &sport, err
+}
+
+func (r *repository) GetSportByCode(code string) (*models.Sport, error) {
+	var sport models.Sport
+	err := r.db.Preload("Positions", orderedPositions).Where("code = ?", code).First(&sport).Error
+	return &sport, err
+}
+
+// GetSportByGameType - Match.GameType holds either the sport code or its display name
+func (r *repository) GetSportByGameType(gameType string) (*models.Sport, error) {
+	var sport models.Sport
+	err := r.db.Preload("Positions", orderedPositions).
+		Where("LOWER(code) = LOWER(?) OR LOWER(name) = LOWER(?)", gameType, gameType).
+		First(&sport).Error
+	return &sport, err
+}
+
+func (r *repository) UpdateSport(sport *models.Sport) error {
+	// Omit associations so a preloaded position list is not re-saved
+	return r.db.Omit("Positions").Save(sport).Error
+}
+
+func (r *repository) DeleteSport(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("sport_id = ?", id).Delete(&models.SportPosition{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Sport{}, "id = ?", id).Error
+	})
+}
+
+func (r *repository) CreateSportPosition(position *models.SportPosition) error {
+	return r.db.Create(position).Error
+}
+
+func (r *repository) GetSportPositionByID(id string) (*models.SportPosition, error) {
+	var position models.SportPosition
+	err := r.db.First(&position, "id = ?", id).Error
+	return &position, err
+}
+
+func (r *repository) UpdateSportPosition(position *models.SportPosition) error {
+	return r.db.Save(position).Error
+}
+
+func (r *repository) DeleteSportPosition(id string) error {
+	return r.db.Delete(&models.SportPosition{}, "id = ?", id).Error
+}
+
+// SportUsage is what still refers to a sport, or to one of its positions.
+type SportUsage struct {
+	Matches   int64
+	Templates int64
+	Series    int64
+	Bookings  int64 // Positions only
+}
+
+func (u SportUsage) InUse() bool {
+	return u.Matches > 0 || u.Templates > 0 || u.Series > 0 || u.Bookings > 0
+}
+
+// usageTables pairs each table that names a sport with its count in usage.
+func usageTables(usage *SportUsage) []struct {
+	model interface{}
+	count *int64
+} {
+	return []struct {
+		model interface{}
+		count *int64
+	}{
+		{&models.Match{}, &usage.Matches},
+		{&models.MatchTemplate{}, &usage.Templates},
+		{&models.MatchSeries{}, &usage.Series},
+	}
+}
+
+// lowerGameTypes drops blanks and lower-cases the rest for matching game_type.
+func lowerGameTypes(gameTypes []string) []string {
+	lowered := make([]string, 0, len(gameTypes))
+	for _, gt := range gameTypes {
+		if gt != "" {
+			lowered = append(lowered, strings.ToLower(gt))
+		}
+	}
+	return lowered
+}
+
+// CountSportUsage - matches, templates and series store either the sport code
+// or its display name in game_type
+func (r *repository) CountSportUsage(gameTypes ...string) (SportUsage, error) {
+	var usage SportUsage
+	lowered := lowerGameTypes(gameTypes)
+	if len(lowered) == 0 {
+		return usage, nil
+	}
+	for _, t := range usageTables(&usage) {
+		if err := r.db.Model(t.model).Where("LOWER(game_type) IN ?", lowered).Count(t.count).Error; err != nil {
+			return usage, err
+		}
+	}
+	return usage, nil
+}
+
+// CountPositionUsage - matches, templates and series of the sport whose
+// quotas or prices name the position, and bookings on the sport's matches
+// for it
+func (r *repository) CountPositionUsage(position string, gameTypes ...string) (SportUsage, error) {
+	var usage SportUsage
+	lowered := lowerGameTypes(gameTypes)
+	if len(lowered) == 0 {
+		return usage, nil
+	}
+
+	for _, t := range usageTables(&usage) {
+		err := r.db.Model(t.model).
+			Where("LOWER(game_type) IN ?", lowered).
+			Where("jsonb_exists(position_quotas, ?) OR jsonb_exists(position_prices, ?)", position, position).
+			Count(t.count).Error
+		if err != nil {
+			return usage, err
+		}
+	}
+	sportMatches := r.db.Model(&models.Match{}).Select("id").Where("LOWER(game_type) IN ?", lowered)
+	err := r.db.Model(&models.Booking{}).
+		Where("position = ? AND match_id IN (?)", position, sportMatches).
+		Count(&usage.Bookings).Error
+	return usage, err
+}
+
+// RenameGameType - rewrites game_type on matches, templates and series
+func (r *repository) RenameGameType(oldGameType, newGameType string) error {
+	for _, model := range []interface{}{&models.Match{}, &models.MatchTemplate{}, &models.MatchSeries{}} {
+		if err := r.db.Model(model).Where("LOWER(game_type) = LOWER(?)", oldGameType).Update("game_type", newGameType).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RenameRatingSport - moves player ratings to a sport's new code
+func (r *repository) RenameRatingSport(oldSport, newSport string) error {
+	return r.db.Model(&models.PlayerRating{}).Where("sport = ?", oldSport).Update("sport", newSport).Error
+}
+
 func (r *repository) FixData() error {
 	// Use a valid UUID for the test user to satisfy Postgres strict type checks
 	// This UUID should match what is used in Handlers fallback
@@ -265,40 +812,176 @@ func (r *repository) UpdateTeamMember(memberID string, newTeamID string) error {
 	return r.db.Model(&models.TeamMember{}).Where("id = ?", memberID).Update("team_id", newTeamID).Error
 }
 
+func (r *repository) UpdateTeamStrength(teamID string, strength float64) error {
+	return r.db.Model(&models.Team{}).Where("id = ?", teamID).Update("strength", strength).Error
+}
+
+func (r *repository) DeleteTeamMember(id string) error {
+	return r.db.Delete(&models.TeamMember{}, "id = ?", id).Error
+}
+
+// GetTeamsByMatchID - the teams of the match's published lineup
 func (r *repository) GetTeamsByMatchID(matchID string) ([]models.Team, error) {
 	var teams []models.Team
-	err := r.db.Preload("Members.User").Where("match_id = ?", matchID).Find(&teams).Error
+	published := r.db.Model(&models.TeamLineup{}).Select("id").Where("match_id = ? AND status = ?", matchID, models.LineupPublished)
+	err := r.db.Preload("Members.User").Where("match_id = ? AND lineup_id IN (?)", matchID, published).Order("bench, slot").Find(&teams).Error
 	return teams, err
 }
 
-func (r *repository) DeleteTeamsByMatchID(matchID string) error {
-	// Manual Cascade: Delete members first
-	// 1. Get Team IDs
-	var teamIDs []string
-	r.db.Model(&models.Team{}).Where("match_id = ?", matchID).Pluck("id", &teamIDs)
+func (r *repository) NextLineupVersion(matchID string) (int, error) {
+	var version int
+	err := r.db.Model(&models.TeamLineup{}).Where("match_id = ?", matchID).Select("COALESCE(MAX(version), 0) + 1").Scan(&version).Error
+	return version, err
+}
 
-	if len(teamIDs) > 0 {
-		// 2. Delete Members
-		if err := r.db.Delete(&models.TeamMember{}, "team_id IN ?", teamIDs).Error; err != nil {
-			return err
+func (r *repository) CreateLineup(lineup *models.TeamLineup) error {
+	return r.db.Omit("Teams").Create(lineup).Error
+}
+
+func (r *repository) UpdateLineup(lineup *models.TeamLineup) error {
+	return r.db.Omit("Teams").Save(lineup).Error
+}
+
+// GetLineups - every version of the match's teams, newest first, without the teams
+func (r *repository) GetLineups(matchID string) ([]models.TeamLineup, error) {
+	var lineups []models.TeamLineup
+	err := r.db.Where("match_id = ?", matchID).Order("version DESC").Find(&lineups).Error
+	return lineups, err
+}
+
+func (r *repository) GetLineup(matchID string, version int) (*models.TeamLineup, error) {
+	var lineup models.TeamLineup
+	err := r.db.Preload("Teams", func(db *gorm.DB) *gorm.DB {
+		return db.Order("bench, slot")
+	}).Preload("Teams.Members.User").First(&lineup, "match_id = ? AND version = ?", matchID, version).Error
+	return &lineup, err
+}
+
+func (r *repository) GetLineupByID(id string) (*models.TeamLineup, error) {
+	var lineup models.TeamLineup
+	err := r.db.First(&lineup, "id = ?", id).Error
+	return &lineup, err
+}
+
+// SupersedeLineups marks the match's published lineup as superseded.
+func (r *repository) SupersedeLineups(matchID string) error {
+	return r.db.Model(&models.TeamLineup{}).
+		Where("match_id = ? AND status = ?", matchID, models.LineupPublished).
+		Update("status", models.LineupSuperseded).Error
+}
+
+// GetPlayerRatings - the users' ratings for the sport in the scope, falling
+// back to their unscoped legacy rating
+func (r *repository) GetPlayerRatings(scope models.RatingScope, sport string, userIDs []string) ([]models.PlayerRating, error) {
+	var ratings []models.PlayerRating
+	if len(userIDs) == 0 {
+		return ratings, nil
+	}
+	err := r.db.Where("sport = ? AND user_id IN ?", sport, userIDs).
+		Where("(club_id = ? AND organiser_id = ?) OR (club_id = '' AND organiser_id = '')", scope.ClubID, scope.OrganiserID).
+		Find(&ratings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byUser := make(map[string]int, len(ratings))
+	out := ratings[:0]
+	for _, rating := range ratings {
+		scoped := rating.ClubID != "" || rating.OrganiserID != ""
+		if i, ok := byUser[rating.UserID]; ok {
+			if scoped {
+				out[i] = rating
+			}
+			continue
 		}
+		byUser[rating.UserID] = len(out)
+		out = append(out, rating)
 	}
+	return out, nil
+}
+
+// SavePlayerRating creates or replaces the user's rating for the sport in
+// the rating's scope.
+func (r *repository) SavePlayerRating(rating *models.PlayerRating) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "sport"}, {Name: "club_id"}, {Name: "organiser_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "updated_by_id", "updated_at"}),
+	}).Create(rating).Error
+}
+
+func (r *repository) GetTeamConstraints(matchID string) ([]models.TeamConstraint, error) {
+	var constraints []models.TeamConstraint
+	err := r.db.Where("match_id = ?", matchID).Order("created_at").Find(&constraints).Error
+	return constraints, err
+}
 
-	// 3. Delete Teams
-	return r.db.Delete(&models.Team{}, "match_id = ?", matchID).Error
+func (r *repository) CreateTeamConstraint(constraint *models.TeamConstraint) error {
+	return r.db.Create(constraint).Error
 }
 
-func (r *repository) ListMatches(filter MatchFilter) ([]models.Match, error) {
+func (r *repository) GetTeamsByLineupID(lineupID string) ([]models.Team, error) {
+	var teams []models.Team
+	err := r.db.Where("lineup_id = ?", lineupID).Order("bench, slot").Find(&teams).Error
+	return teams, err
+}
+
+func (r *repository) GetFixtureSchedule(matchID string) (*models.FixtureSchedule, error) {
+	var schedule models.FixtureSchedule
+	err := r.db.First(&schedule, "match_id = ?", matchID).Error
+	return &schedule, err
+}
+
+func (r *repository) CreateFixtureSchedule(schedule *models.FixtureSchedule) error {
+	return r.db.Create(schedule).Error
+}
+
+// DeleteFixtures removes the match's schedule with all its games.
+func (r *repository) DeleteFixtures(matchID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.Fixture{}, "match_id = ?", matchID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.FixtureSchedule{}, "match_id = ?", matchID).Error
+	})
+}
+
+func (r *repository) GetFixtures(matchID string) ([]models.Fixture, error) {
+	var fixtures []models.Fixture
+	err := r.db.Preload("HomeTeam").Preload("AwayTeam").Where("match_id = ?", matchID).Order("seq").Find(&fixtures).Error
+	return fixtures, err
+}
+
+func (r *repository) CreateFixture(fixture *models.Fixture) error {
+	return r.db.Omit(clause.Associations).Create(fixture).Error
+}
+
+func (r *repository) UpdateFixture(fixture *models.Fixture) error {
+	return r.db.Omit(clause.Associations).Save(fixture).Error
+}
+
+func (r *repository) DeleteFixture(id string) error {
+	return r.db.Delete(&models.Fixture{}, "id = ?", id).Error
+}
+
+func (r *repository) DeleteTeamConstraint(id string) error {
+	return r.db.Delete(&models.TeamConstraint{}, "id = ?", id).Error
+}
+
+func (r *repository) ListMatches(filter MatchFilter, page PageQuery) (*models.Page[models.Match], error) {
 	fmt.Printf("[Repo] ListMatches: %+v\n", filter)
-	var matches []models.Match
 	now := time.Now()
 	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 
-	query := r.db.Preload("Bookings").Preload("Creator").Preload("Club").Order("date ASC")
+	query := r.db.Model(&models.Match{})
+	key := keyset{Sort: "matches.date", ID: "matches.id"}
+	if filter.Near != nil {
+		query = geoWithin(query, filter.Near)
+		key = nearestFirst("matches", filter.Near)
+	}
 
 	// Only filter by date if looking for public matches (browsing)
 	// If filtering by "My Created" or "My Joined", show history too.
-	if filter.CreatorID == "" && filter.JoinedUserID == "" {
+	if filter.CreatorID == "" && filter.JoinedUserID == "" && filter.DateFrom == nil {
 		query = query.Where("date >= ?", startOfDay)
 	}
 
@@ -319,16 +1002,11 @@ func (r *repository) ListMatches(filter MatchFilter) ([]models.Match, error) {
 
 	if filter.Status != "" {
 		// If Status is "all", we don't filter (useful for owners)
-		// Otherwise exact match (e.g. "published", "draft")
 		if filter.Status != "all" {
-			// Handle legacy "open" vs "published" if needed, but we try to stick to "published"
-			// If we have mixed data, we might need OR.
-			if filter.Status == "published" {
-				query = query.Where("status = ? OR status = ?", "published", "open")
-			} else {
-				query = query.Where("status = ?", filter.Status)
-			}
+			query = query.Where("status = ?", filter.Status)
 		}
+	} else if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
 	}
 
 	if filter.GameType != "" {
@@ -342,9 +1020,69 @@ func (r *repository) ListMatches(filter MatchFilter) ([]models.Match, error) {
 		query = query.Where("title ILIKE ? OR location ILIKE ?", searchPattern, searchPattern)
 	}
 
-	offset := (filter.Page - 1) * filter.Limit
-	err := query.Limit(filter.Limit).Offset(offset).Find(&matches).Error
-	return matches, err
+	query = applySearchFilters(query, filter)
+
+	return findPage(query, page, key, func(q *gorm.DB) *gorm.DB {
+		// Bookings are left out: lists show CountMatchBookings instead
+		q = q.Preload("Creator").Preload("Club")
+		if filter.Near != nil {
+			q = withDistance(q, "matches", filter.Near)
+		}
+		return q
+	}, func(m *models.Match) pageCursor {
+		if filter.Near != nil {
+			return distanceCursor(m.DistanceKm, m.ID)
+		}
+		return timeCursor(m.Date, m.ID)
+	})
+}
+
+// BookingCount is one row of CountMatchBookings.
+type BookingCount struct {
+	MatchID  string
+	Position models.Position
+	Status   models.BookingStatus
+	Count    int
+}
+
+// CountMatchBookings tallies the active bookings of the given matches by
+// position and status in one aggregate query.
+func (r *repository) CountMatchBookings(matchIDs []string) ([]BookingCount, error) {
+	var counts []BookingCount
+	if len(matchIDs) == 0 {
+		return counts, nil
+	}
+	err := r.db.Model(&models.Booking{}).
+		Select("match_id, position, status, COUNT(*) AS count").
+		Where("match_id IN ? AND status IN ?", matchIDs,
+			[]models.BookingStatus{models.StatusConfirmed, models.StatusOffered, models.StatusWaitlist}).
+		Group("match_id, position, status").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// GetUserMatchBookings - the user's latest non-cancelled booking on each of the given matches
+func (r *repository) GetUserMatchBookings(userID string, matchIDs []string) ([]models.Booking, error) {
+	var bookings []models.Booking
+	if len(matchIDs) == 0 {
+		return bookings, nil
+	}
+	err := r.db.Where("user_id = ? AND match_id IN ? AND status <> ?", userID, matchIDs, models.StatusCancelled).
+		Order("created_at ASC").
+		Find(&bookings).Error
+	return bookings, err
+}
+
+// GetMatchIDsDueForAdvance - matches whose registration close or kick-off has
+// passed, plus every match in progress (its end time depends on the duration)
+func (r *repository) GetMatchIDsDueForAdvance(now time.Time) ([]string, error) {
+	var ids []string
+	err := r.db.Model(&models.Match{}).
+		Where("status = ? AND (date <= ? OR registration_closes_at <= ?)", models.MatchPublished, now, now).
+		Or("status = ? AND date <= ?", models.MatchRegistrationClosed, now).
+		Or("status = ?", models.MatchInProgress).
+		Pluck("id", &ids).Error
+	return ids, err
 }
 
 func (r *repository) GetBookingByID(id string) (*models.Booking, error) {
@@ -353,6 +1091,12 @@ func (r *repository) GetBookingByID(id string) (*models.Booking, error) {
 	return &booking, err
 }
 
+func (r *repository) GetBookingByIDLock(id string) (*models.Booking, error) {
+	var booking models.Booking
+	err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, "id = ?", id).Error
+	return &booking, err
+}
+
 func (r *repository) RunTransaction(fn func(repo Repository) error) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		txRepo := NewRepository(tx)
@@ -398,7 +1142,7 @@ func (r *repository) GetMatchByDate(date string) (*models.Match, error) {
 
 func (r *repository) GetMatchByID(id string) (*models.Match, error) {
 	var match models.Match
-	err := r.db.Preload("Bookings.User").Preload("Bookings").Preload("Creator").Preload("Club").First(&match, "id = ?", id).Error
+	err := r.db.Preload("Bookings.User").Preload("Bookings").Preload("Creator").Preload("Club").Preload("Venue").First(&match, "id = ?", id).Error
 	return &match, err
 }
 
@@ -438,10 +1182,60 @@ func (r *repository) GetWaitlist(matchID string, position models.Position) ([]mo
 	return bookings, err
 }
 
+// GetExpiredOfferMatchIDs - matches still going ahead with at least one
+// waitlist offer past its deadline
+func (r *repository) GetExpiredOfferMatchIDs(now time.Time) ([]string, error) {
+	var ids []string
+	live := r.db.Table("matches").Select("id").
+		Where("status NOT IN ?", []models.MatchStatus{models.MatchCancelled, models.MatchCompleted})
+	err := r.db.Model(&models.Booking{}).
+		Where("status = ? AND offer_expires_at < ? AND match_id IN (?)", models.StatusOffered, now, live).
+		Distinct().Pluck("match_id", &ids).Error
+	return ids, err
+}
+
+func (r *repository) CreateBookingHistory(entry *models.BookingHistory) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *repository) GetBookingHistory(bookingID string) ([]models.BookingHistory, error) {
+	var history []models.BookingHistory
+	err := r.db.Where("booking_id = ?", bookingID).Order("created_at ASC").Find(&history).Error
+	return history, err
+}
 
-// GetAllUsers - get all users
-func (r *repository) GetAllUsers() ([]models.User, error) {
-	var users []models.User
-	err := r.db.Find(&users).Error
-	return users, err
+// GetMatchWaitlist - waitlisted bookings of every position, by their place
+// in their position's queue, then in the order they joined
+func (r *repository) GetMatchWaitlist(matchID string) ([]models.Booking, error) {
+	var bookings []models.Booking
+	err := r.db.Where("match_id = ? AND status = ?", matchID, models.StatusWaitlist).
+		Order("waitlist_order ASC, created_at ASC").Find(&bookings).Error
+	return bookings, err
+}
+
+// ListUsers - paginated user search for the admin panel, returns the total match count too
+func (r *repository) ListUsers(filter UserFilter, page PageQuery) (*models.Page[models.User], error) {
+	query := r.db.Model(&models.User{})
+
+	if filter.Search != "" {
+		searchPattern := "%" + filter.Search + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ? OR phone ILIKE ?", searchPattern, searchPattern, searchPattern)
+	}
+
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	if filter.Status != "" {
+		if filter.Status == string(models.UserStatusActive) {
+			// Rows created before the status column existed are active too
+			query = query.Where("status = ? OR status IS NULL OR status = ''", filter.Status)
+		} else {
+			query = query.Where("status = ?", filter.Status)
+		}
+	}
+
+	return findPage(query, page, newestFirst("users"), nil, func(u *models.User) pageCursor {
+		return timeCursor(u.CreatedAt, u.ID)
+	})
 }
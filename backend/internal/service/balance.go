@@ -0,0 +1,197 @@
+package service
+
+import (
+	"math"
+	"math/rand"
+	"reserve_game/internal/models"
+	"sort"
+)
+
+// maxBalanceSwaps bounds the swap pass; each swap strictly narrows the gap,
+// so it normally stops long before this.
+const maxBalanceSwaps = 200
+
+// rosterPlayer is an eligible booking with the rating it is balanced by.
+type rosterPlayer struct {
+	Booking models.Booking
+	Rating  float64
+	Fixed   bool // Placed by a constraint; never swapped
+}
+
+type rosterTeam struct {
+	Players    []rosterPlayer
+	Strength   float64
+	byPosition map[models.Position]int
+	members    map[string]bool // User IDs
+}
+
+func (t *rosterTeam) add(p rosterPlayer) {
+	t.Players = append(t.Players, p)
+	t.Strength += p.Rating
+	t.byPosition[p.Booking.Position]++
+	t.members[p.Booking.UserID] = true
+}
+
+// balanceTeams splits the planned players into n teams. Pinned units go to
+// their team and keep-together units to the smallest, weakest team that
+// breaks no keep-apart rule, biggest units first. Each position of the
+// remaining players is then dealt out on its own, strongest player first, to
+// the team with the fewest of that position (then the fewest players, then
+// the weakest), again avoiding keep-apart clashes, so positions spread
+// evenly. Swaps of same-position players between teams then run while they
+// narrow the gap between the strongest and weakest team. rng only orders
+// equally rated players and equal units, so the same seed always gives the
+// same split.
+func balanceTeams(plan *teamPlan, n int, rng *rand.Rand) []rosterTeam {
+	teams := make([]rosterTeam, n)
+	for i := range teams {
+		teams[i].byPosition = make(map[models.Position]int)
+		teams[i].members = make(map[string]bool)
+	}
+
+	var free []teamUnit
+	for _, u := range plan.units {
+		if u.slot < 0 {
+			free = append(free, u)
+			continue
+		}
+		for _, p := range u.players {
+			p.Fixed = true
+			teams[u.slot].add(p)
+		}
+	}
+	rng.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	sort.SliceStable(free, func(i, j int) bool { return len(free[i].players) > len(free[j].players) })
+	for _, u := range free {
+		clashes := func(t *rosterTeam) int {
+			n := 0
+			for _, p := range u.players {
+				n += plan.clashes(t, p, "")
+			}
+			return n
+		}
+		best := 0
+		for i := 1; i < n; i++ {
+			a, b := &teams[i], &teams[best]
+			if ca, cb := clashes(a), clashes(b); ca != cb {
+				if ca < cb {
+					best = i
+				}
+			} else if len(a.Players) != len(b.Players) {
+				if len(a.Players) < len(b.Players) {
+					best = i
+				}
+			} else if a.Strength < b.Strength {
+				best = i
+			}
+		}
+		for _, p := range u.players {
+			p.Fixed = true
+			teams[best].add(p)
+		}
+	}
+
+	groups := make(map[models.Position][]rosterPlayer)
+	var positions []models.Position
+	for _, p := range plan.singles {
+		pos := p.Booking.Position
+		if _, ok := groups[pos]; !ok {
+			positions = append(positions, pos)
+		}
+		groups[pos] = append(groups[pos], p)
+	}
+	// Scarce positions (usually keepers) first, while every team is still open to them
+	sort.Slice(positions, func(i, j int) bool {
+		if len(groups[positions[i]]) != len(groups[positions[j]]) {
+			return len(groups[positions[i]]) < len(groups[positions[j]])
+		}
+		return positions[i] < positions[j]
+	})
+
+	for _, pos := range positions {
+		group := groups[pos]
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		sort.SliceStable(group, func(i, j int) bool { return group[i].Rating > group[j].Rating })
+		for _, p := range group {
+			best := 0
+			for i := 1; i < n; i++ {
+				ca, cb := plan.clashes(&teams[i], p, ""), plan.clashes(&teams[best], p, "")
+				if ca < cb || (ca == cb && fitsBetter(&teams[i], &teams[best], pos)) {
+					best = i
+				}
+			}
+			teams[best].add(p)
+		}
+	}
+
+	improveBalance(teams, plan)
+	plan.checkApart(teams)
+	return teams
+}
+
+func fitsBetter(a, b *rosterTeam, pos models.Position) bool {
+	if a.byPosition[pos] != b.byPosition[pos] {
+		return a.byPosition[pos] < b.byPosition[pos]
+	}
+	if len(a.Players) != len(b.Players) {
+		return len(a.Players) < len(b.Players)
+	}
+	return a.Strength < b.Strength
+}
+
+// improveBalance applies the same-position swap that narrows the strength gap
+// most, until no swap helps. Fixed players stay put, and no swap adds a
+// keep-apart clash.
+func improveBalance(teams []rosterTeam, plan *teamPlan) {
+	for n := 0; n < maxBalanceSwaps; n++ {
+		best := strengthGap(teams)
+		ba, bi, bb, bj := -1, 0, 0, 0
+		for a := range teams {
+			for b := a + 1; b < len(teams); b++ {
+				for i, pa := range teams[a].Players {
+					for j, pb := range teams[b].Players {
+						if pa.Fixed || pb.Fixed || pa.Booking.Position != pb.Booking.Position || pa.Rating == pb.Rating {
+							continue
+						}
+						before := plan.clashes(&teams[a], pa, "") + plan.clashes(&teams[b], pb, "")
+						after := plan.clashes(&teams[b], pa, pb.Booking.UserID) + plan.clashes(&teams[a], pb, pa.Booking.UserID)
+						if after > before {
+							continue
+						}
+						delta := pb.Rating - pa.Rating
+						teams[a].Strength += delta
+						teams[b].Strength -= delta
+						if gap := strengthGap(teams); gap < best-1e-9 {
+							best, ba, bi, bb, bj = gap, a, i, b, j
+						}
+						teams[a].Strength -= delta
+						teams[b].Strength += delta
+					}
+				}
+			}
+		}
+		if ba < 0 {
+			return
+		}
+		pa, pb := teams[ba].Players[bi], teams[bb].Players[bj]
+		teams[ba].Players[bi], teams[bb].Players[bj] = pb, pa
+		teams[ba].Strength += pb.Rating - pa.Rating
+		teams[bb].Strength += pa.Rating - pb.Rating
+		delete(teams[ba].members, pa.Booking.UserID)
+		delete(teams[bb].members, pb.Booking.UserID)
+		teams[ba].members[pb.Booking.UserID] = true
+		teams[bb].members[pa.Booking.UserID] = true
+	}
+}
+
+func strengthGap(teams []rosterTeam) float64 {
+	if len(teams) == 0 {
+		return 0
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, t := range teams {
+		lo = math.Min(lo, t.Strength)
+		hi = math.Max(hi, t.Strength)
+	}
+	return hi - lo
+}
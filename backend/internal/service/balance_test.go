@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"reserve_game/internal/models"
+)
+
+// roster is 12 players: the first keepers are gk, the rest alternate
+// between defenders and forwards, rated 1 to 3 in turn so the seed has ties
+// to order.
+func roster(keepers int) []rosterPlayer {
+	players := make([]rosterPlayer, 12)
+	for i := range players {
+		pos := models.PositionDefender
+		switch {
+		case i < keepers:
+			pos = models.PositionGK
+		case i%2 == 1:
+			pos = models.PositionForward
+		}
+		players[i] = rosterPlayer{
+			Booking: models.Booking{ID: fmt.Sprintf("B%d", i), UserID: fmt.Sprintf("U%d", i), Position: pos},
+			Rating:  float64(i%3 + 1),
+		}
+	}
+	return players
+}
+
+// split renders the teams as their user IDs, in placement order.
+func split(teams []rosterTeam) string {
+	var out []string
+	for _, t := range teams {
+		var ids []string
+		for _, p := range t.Players {
+			ids = append(ids, p.Booking.UserID)
+		}
+		out = append(out, strings.Join(ids, ","))
+	}
+	return strings.Join(out, " | ")
+}
+
+// teamOf maps each user to the index of their team.
+func teamOf(teams []rosterTeam) map[string]int {
+	of := make(map[string]int)
+	for i, t := range teams {
+		for _, p := range t.Players {
+			of[p.Booking.UserID] = i
+		}
+	}
+	return of
+}
+
+func TestBalanceTeamsSeed(t *testing.T) {
+	constraints := []models.TeamConstraint{
+		{ID: "C1", Kind: models.ConstraintTogether, UserID: "U4", OtherUserID: "U5"},
+		{ID: "C2", Kind: models.ConstraintApart, UserID: "U6", OtherUserID: "U7"},
+	}
+	generate := func(seed int64) string {
+		plan := planTeams(roster(3), 3, constraints, nil)
+		return split(balanceTeams(plan, 3, rand.New(rand.NewSource(seed))))
+	}
+
+	first := generate(42)
+	for i := 0; i < 5; i++ {
+		if again := generate(42); again != first {
+			t.Fatalf("seed 42 gave %q, then %q", first, again)
+		}
+	}
+
+	differs := false
+	for seed := int64(1); seed <= 20 && !differs; seed++ {
+		differs = generate(seed) != first
+	}
+	if !differs {
+		t.Errorf("seeds 1-20 all gave the split of seed 42: the seed isn't used")
+	}
+}
+
+func TestBalanceTeamsSpreadsKeepers(t *testing.T) {
+	for _, tc := range []struct {
+		keepers, teams int
+	}{
+		{2, 2},
+		{3, 3},
+		{4, 3},
+		{2, 4},
+	} {
+		t.Run(fmt.Sprintf("%d keepers %d teams", tc.keepers, tc.teams), func(t *testing.T) {
+			for seed := int64(0); seed < 10; seed++ {
+				plan := planTeams(roster(tc.keepers), tc.teams, nil, nil)
+				teams := balanceTeams(plan, tc.teams, rand.New(rand.NewSource(seed)))
+				min, max := tc.keepers, 0
+				for _, team := range teams {
+					n := 0
+					for _, p := range team.Players {
+						if p.Booking.Position == models.PositionGK {
+							n++
+						}
+					}
+					if n < min {
+						min = n
+					}
+					if n > max {
+						max = n
+					}
+				}
+				if max-min > 1 {
+					t.Errorf("seed %d: keepers per team range from %d to %d: %s", seed, min, max, split(teams))
+				}
+			}
+		})
+	}
+}
+
+func TestBalanceTeamsConstraints(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		constraints []models.TeamConstraint
+		check       func(of map[string]int) error
+	}{
+		{
+			name: "lock",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintLock, UserID: "U9", Slot: 2},
+				{ID: "C2", Kind: models.ConstraintLock, UserID: "U8", Slot: 0},
+			},
+			check: func(of map[string]int) error {
+				if of["U9"] != 2 || of["U8"] != 0 {
+					return fmt.Errorf("U9 in team %d, U8 in team %d", of["U9"], of["U8"])
+				}
+				return nil
+			},
+		},
+		{
+			name: "together",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintTogether, UserID: "U1", OtherUserID: "U3"},
+				{ID: "C2", Kind: models.ConstraintTogether, UserID: "U3", OtherUserID: "U10"},
+			},
+			check: func(of map[string]int) error {
+				if of["U1"] != of["U3"] || of["U3"] != of["U10"] {
+					return fmt.Errorf("U1, U3 and U10 in teams %d, %d and %d", of["U1"], of["U3"], of["U10"])
+				}
+				return nil
+			},
+		},
+		{
+			name: "apart",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintApart, UserID: "U9", OtherUserID: "U11"},
+				{ID: "C2", Kind: models.ConstraintApart, UserID: "U9", OtherUserID: "U7"},
+				{ID: "C3", Kind: models.ConstraintApart, UserID: "U7", OtherUserID: "U11"},
+			},
+			check: func(of map[string]int) error {
+				if of["U9"] == of["U11"] || of["U9"] == of["U7"] || of["U7"] == of["U11"] {
+					return fmt.Errorf("U7, U9 and U11 in teams %d, %d and %d", of["U7"], of["U9"], of["U11"])
+				}
+				return nil
+			},
+		},
+		{
+			name: "together with a pin",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintLock, UserID: "U5", Slot: 1},
+				{ID: "C2", Kind: models.ConstraintTogether, UserID: "U5", OtherUserID: "U6"},
+				{ID: "C3", Kind: models.ConstraintApart, UserID: "U6", OtherUserID: "U2"},
+			},
+			check: func(of map[string]int) error {
+				if of["U5"] != 1 || of["U6"] != 1 || of["U2"] == 1 {
+					return fmt.Errorf("U5, U6 and U2 in teams %d, %d and %d", of["U5"], of["U6"], of["U2"])
+				}
+				return nil
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for seed := int64(0); seed < 10; seed++ {
+				plan := planTeams(roster(3), 3, tc.constraints, nil)
+				teams := balanceTeams(plan, 3, rand.New(rand.NewSource(seed)))
+				if len(plan.unmet) > 0 {
+					t.Errorf("seed %d: unmet %+v", seed, plan.unmet)
+				}
+				if err := tc.check(teamOf(teams)); err != nil {
+					t.Errorf("seed %d: %v: %s", seed, err, split(teams))
+				}
+			}
+		})
+	}
+}
+
+func TestPlanTeamsUnmet(t *testing.T) {
+	names := map[string]string{"U1": "Andi", "U2": "Budi"}
+	for _, tc := range []struct {
+		name        string
+		constraints []models.TeamConstraint
+		unmet       string // Constraint ID; "" when any of them may be
+		reason      string // Part of the reason
+	}{
+		{
+			name: "pinned pair on different teams",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintLock, UserID: "U1", Slot: 0},
+				{ID: "C2", Kind: models.ConstraintLock, UserID: "U2", Slot: 1},
+				{ID: "C3", Kind: models.ConstraintTogether, UserID: "U1", OtherUserID: "U2"},
+			},
+			unmet:  "C3",
+			reason: "Andi and Budi are pinned to different teams",
+		},
+		{
+			name: "pair kept together and apart",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintTogether, UserID: "U1", OtherUserID: "U2"},
+				{ID: "C2", Kind: models.ConstraintApart, UserID: "U2", OtherUserID: "U1"},
+			},
+			unmet:  "C2",
+			reason: "Budi and Andi are also kept together",
+		},
+		{
+			name: "pin past the team count",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintLock, UserID: "U1", Slot: 3},
+			},
+			unmet:  "C1",
+			reason: "pinned to team 4 but only 3 teams",
+		},
+		{
+			name: "player not in the teams",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintApart, UserID: "U1", OtherUserID: "U99"},
+			},
+			unmet:  "C1",
+			reason: "U99 isn't in the teams",
+		},
+		{
+			name: "more players kept apart than teams",
+			constraints: []models.TeamConstraint{
+				{ID: "C1", Kind: models.ConstraintApart, UserID: "U1", OtherUserID: "U2"},
+				{ID: "C2", Kind: models.ConstraintApart, UserID: "U1", OtherUserID: "U3"},
+				{ID: "C3", Kind: models.ConstraintApart, UserID: "U1", OtherUserID: "U4"},
+				{ID: "C4", Kind: models.ConstraintApart, UserID: "U2", OtherUserID: "U3"},
+				{ID: "C5", Kind: models.ConstraintApart, UserID: "U2", OtherUserID: "U4"},
+				{ID: "C6", Kind: models.ConstraintApart, UserID: "U3", OtherUserID: "U4"},
+			},
+			reason: "had to share team",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			plan := planTeams(roster(3), 3, tc.constraints, names)
+			balanceTeams(plan, 3, rand.New(rand.NewSource(1)))
+			if len(plan.unmet) != 1 {
+				t.Fatalf("unmet %+v, want one", plan.unmet)
+			}
+			if tc.unmet != "" && plan.unmet[0].ConstraintID != tc.unmet {
+				t.Errorf("unmet %s, want %s", plan.unmet[0].ConstraintID, tc.unmet)
+			}
+			if !strings.Contains(plan.unmet[0].Reason, tc.reason) {
+				t.Errorf("reason %q, want it to mention %q", plan.unmet[0].Reason, tc.reason)
+			}
+		})
+	}
+}
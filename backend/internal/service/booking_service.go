@@ -1,9 +1,9 @@
 package service
 
 import (
-	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reserve_game/internal/models"
 	"reserve_game/internal/repository"
 	"time"
@@ -26,6 +26,9 @@ func (s *BookingService) JoinMatch(userID string, matchID string, position model
 		if err != nil {
 			return err
 		}
+		if err := match.CheckRegistration(time.Now()); err != nil {
+			return err
+		}
 
 		// 2. Get existing bookings
 		bookings, err := repo.GetBookingsByMatchID(matchID)
@@ -40,75 +43,40 @@ func (s *BookingService) JoinMatch(userID string, matchID string, position model
 			}
 		}
 
-		// 4. Calculate status based on quota
-		confirmedCount := 0
-		maxWaitlistOrder := 0
-		for _, b := range bookings {
-			if b.Position == position {
-				if b.Status == models.StatusConfirmed {
-					confirmedCount++
-				} else if b.Status == models.StatusWaitlist {
-					if b.WaitlistOrder > maxWaitlistOrder {
-						maxWaitlistOrder = b.WaitlistOrder
-					}
-				}
-			}
+		// 4. Position must be one of the match's sport positions
+		slots, err := ResolvePositions(repo, match)
+		if err != nil {
+			return err
+		}
+		slot, err := findSlot(slots, position)
+		if err != nil {
+			return err
 		}
 
-		// Parse Quota from Match
-		quota := 0
-		// Default quotas if empty
-		if match.PositionQuotas == "" {
-			switch position {
-			case models.PositionGK:
-				quota = 2 // Default
-			default:
-				quota = 15 // Default
-			}
-		} else {
-			var quotas map[string]int
-			if err := json.Unmarshal([]byte(match.PositionQuotas), &quotas); err != nil {
-				// Fallback if bad JSON
-				quota = 15
-			} else {
-				if q, ok := quotas[string(position)]; ok {
-					quota = q
-				} else {
-					quota = 0 // Position not allowed? or unlimited? Let's say 0 means blocked/waitlist only if strict.
-					// Or maybe generic player default.
-					// Let's assume if not implicit, it's 0 (full/waitlist).
-					// But for backward compatibility with existing "player_front", "player_back",
-					// we should handle safely.
-					if position == "player" || position == "player_front" || position == "player_back" {
-						// Check if there is a generic "player" quota
-						if qDetails, ok := quotas["player"]; ok {
-							quota = qDetails
-						} else {
-							// Try "player_front" specifically
-							if qPF, okPF := quotas["player_front"]; okPF {
-								quota = qPF
-							} else {
-								quota = 100 // Fallback open
-							}
-						}
-					}
-				}
+		// 5. Calculate status based on position quota AND the match-wide MaxPlayers cap
+		positionTaken, totalTaken := takenCounts(bookings)
+		maxWaitlistOrder := 0
+		for _, b := range bookings {
+			onWaitlist := b.Status == models.StatusWaitlist || b.Status == models.StatusOffered
+			if b.Position == position && onWaitlist && b.WaitlistOrder > maxWaitlistOrder {
+				maxWaitlistOrder = b.WaitlistOrder
 			}
 		}
 
 		status := models.StatusConfirmed
 		waitlistOrder := 0
-		if confirmedCount >= quota {
+		if !hasRoom(match, slot, positionTaken[position], totalTaken) {
 			status = models.StatusWaitlist
 			waitlistOrder = maxWaitlistOrder + 1
 		}
 
-		// 4. Create booking
+		// 6. Create booking
 		newBooking := &models.Booking{
 			MatchID:       matchID,
 			UserID:        userID,
 			Position:      position,
 			Status:        status,
+			Amount:        BookingPrice(match, position),
 			WaitlistOrder: waitlistOrder,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
@@ -117,6 +85,11 @@ func (s *BookingService) JoinMatch(userID string, matchID string, position model
 		if err := repo.CreateBooking(newBooking); err != nil {
 			return err
 		}
+		if status == models.StatusWaitlist {
+			if err := recordHistory(repo, newBooking, models.EventWaitlisted, "", ""); err != nil {
+				return err
+			}
+		}
 		booking = newBooking
 		return nil
 	})
@@ -124,13 +97,28 @@ func (s *BookingService) JoinMatch(userID string, matchID string, position model
 	return booking, err
 }
 
-func (s *BookingService) CancelBooking(bookingID string, userID string, isAdmin bool) error {
-	return s.Repo.RunTransaction(func(repo repository.Repository) error {
+// CancelBooking applies the match's cancellation policy to a player dropping
+// out. Organisers can always remove a player, free of charge.
+func (s *BookingService) CancelBooking(bookingID string, userID string, isAdmin bool, reason string) (*models.CancellationOutcome, error) {
+	var outcome *models.CancellationOutcome
+
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
 		booking, err := repo.GetBookingByID(bookingID)
 		if err != nil {
 			return err
 		}
 
+		// Serialize with JoinMatch so promotions see a consistent count,
+		// then re-read the booking under the lock.
+		match, err := repo.GetMatchByIDLock(booking.MatchID)
+		if err != nil {
+			return err
+		}
+		booking, err = repo.GetBookingByID(bookingID)
+		if err != nil {
+			return err
+		}
+
 		if booking.UserID != userID && !isAdmin {
 			fmt.Printf("[CancelBooking] Denied. BookingUserID=%s, RequestUserID=%s, IsAdmin=%v\n", booking.UserID, userID, isAdmin)
 			return errors.New("unauthorized to cancel this booking")
@@ -140,47 +128,264 @@ func (s *BookingService) CancelBooking(bookingID string, userID string, isAdmin
 			return errors.New("booking already cancelled")
 		}
 
-		wasConfirmed := false
-		if booking.Status == models.StatusConfirmed {
-			wasConfirmed = true
+		now := time.Now()
+		policy := EffectiveCancellationPolicy(repo, match)
+		removedByOrganiser := booking.UserID != userID
+
+		rule := models.CancelRuleOrganiser
+		fee := 0.0
+		needsReplacement := false
+		if !removedByOrganiser {
+			rule, fee, needsReplacement, err = evaluateCancellation(policy, booking, match, now)
+			if err != nil {
+				return err
+			}
 		}
 
+		// Confirmed players and open offers both hold a spot
+		heldSpot := booking.Status == models.StatusConfirmed || booking.Status == models.StatusOffered
+
 		// Update to cancelled
 		booking.Status = models.StatusCancelled
 		booking.WaitlistOrder = 0
+		booking.OfferExpiresAt = nil
+		booking.CancelledAt = &now
+		booking.CancelledByID = &userID
+		booking.CancelReason = reason
+		booking.CancellationRule = rule
+		booking.CancellationFee = fee
+		booking.IsPaid = booking.Outstanding() == 0 // Only the fee is owed now
+		booking.UpdatedAt = now
 		if err := repo.UpdateBooking(booking); err != nil {
 			return err
 		}
+		if err := expireIntents(repo, booking.ID); err != nil {
+			return err
+		}
 
-		// If confirmed booking was cancelled, try to promote a waitlist user
-		if wasConfirmed {
-			waitlist, err := repo.GetWaitlist(booking.MatchID, booking.Position)
+		// A spot freed up: offer it to the next players on the waitlist
+		if heldSpot {
+			offered, err := offerWaitlist(repo, match, now)
 			if err != nil {
 				return err
 			}
+			if needsReplacement && offered == 0 {
+				return fmt.Errorf("cancellation is closed %d hours before kick-off unless a waitlisted player can take your spot", policy.CancelCutoffHours)
+			}
+		}
 
-			if len(waitlist) > 0 {
-				nextBooking := &waitlist[0] // Get first waitlist (ordered by waitlist_order ASC)
-				nextBooking.Status = models.StatusConfirmed
-				nextBooking.WaitlistOrder = 0
-				if err := repo.UpdateBooking(nextBooking); err != nil {
+		// Paid bookings get a refund (minus any late fee). Removal by the organiser,
+		// and leaving without ever holding a spot, are always refunded in full.
+		refund, err := createRefund(repo, booking, match, "Booking cancelled", removedByOrganiser || !heldSpot)
+		if err != nil {
+			return err
+		}
+
+		outcome = &models.CancellationOutcome{
+			Booking:            booking,
+			Rule:               rule,
+			Fee:                fee,
+			HoursBeforeKickoff: math.Max(0, match.Date.Sub(now).Hours()),
+			Policy:             policy,
+			Refund:             refund,
+		}
+		return nil
+	})
+
+	return outcome, err
+}
+
+// CancelMatch cancels the match with every booking still on it, closing any
+// open waitlist offers, and refunds every paid booking in full (or to the
+// club wallet under the credit policy).
+func (s *BookingService) CancelMatch(matchID string, reason string) (*models.Match, []models.Refund, error) {
+	var match *models.Match
+	var refunds []models.Refund
+
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		var err error
+		match, err = repo.GetMatchByIDLock(matchID)
+		if err != nil {
+			return err
+		}
+		if err := TransitionMatch(match, models.MatchCancelled, time.Now()); err != nil {
+			return err
+		}
+		match.CancelReason = reason
+		if err := repo.UpdateMatch(match); err != nil {
+			return err
+		}
+
+		bookings, err := repo.GetBookingsByMatchID(matchID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for i := range bookings {
+			b := &bookings[i]
+			from := b.Status
+			// A cutoff cancellation still waiting on a replacement is owed its
+			// money back too; other cancelled bookings are settled already.
+			if from == models.StatusCancelled && b.CancellationRule != models.CancelRuleReplacePending {
+				continue
+			}
+			if from != models.StatusCancelled {
+				b.Status = models.StatusCancelled
+				b.WaitlistOrder = 0
+				b.OfferExpiresAt = nil
+				b.CancelledAt = &now
+				b.CancelReason = reason
+			}
+			b.CancellationRule = models.CancelRuleMatchCancelled
+			b.CancellationFee = 0
+			b.IsPaid = b.Outstanding() == 0
+			b.UpdatedAt = now
+			if err := repo.UpdateBooking(b); err != nil {
+				return err
+			}
+			if err := expireIntents(repo, b.ID); err != nil {
+				return err
+			}
+			if from == models.StatusWaitlist || from == models.StatusOffered {
+				if err := recordHistory(repo, b, models.EventMatchCancelled, from, reason); err != nil {
 					return err
 				}
 			}
-		}
 
+			refund, err := createRefund(repo, b, match, "Match cancelled: "+reason, true)
+			if err != nil {
+				return err
+			}
+			if refund != nil {
+				refunds = append(refunds, *refund)
+			}
+		}
 		return nil
 	})
+
+	return match, refunds, err
 }
 
-func (s *BookingService) SetPaidStatus(bookingID string, isPaid bool) error {
+// SetPaidStatus is the organiser's manual override of a booking's payment.
+// With amountPaid nil the booking is marked fully paid, or unpaid; marking it
+// unpaid keeps what the payment gateway settled, so a later refund still
+// returns it. Otherwise the given amount is recorded. Every change is written
+// to the override log.
+func (s *BookingService) SetPaidStatus(bookingID string, actorID string, isPaid bool, amountPaid *float64, note string) error {
 	return s.Repo.RunTransaction(func(repo repository.Repository) error {
-		booking, err := repo.GetBookingByID(bookingID)
+		booking, err := repo.GetBookingByIDLock(bookingID)
 		if err != nil {
 			return err
 		}
 
-		booking.IsPaid = isPaid
-		return repo.UpdateBooking(booking)
+		override := &models.PaymentOverride{
+			BookingID:          booking.ID,
+			ActorID:            actorID,
+			PreviousIsPaid:     booking.IsPaid,
+			PreviousAmountPaid: booking.AmountPaid,
+			Note:               note,
+			CreatedAt:          time.Now(),
+		}
+
+		if amountPaid != nil {
+			if *amountPaid < 0 {
+				return errors.New("amount paid cannot be negative")
+			}
+			booking.AmountPaid = *amountPaid
+			booking.IsPaid = booking.Outstanding() == 0
+		} else {
+			booking.IsPaid = isPaid
+			if isPaid {
+				booking.AmountPaid = booking.Amount
+			} else if booking.AmountPaid, err = settledAmount(repo, booking.ID); err != nil {
+				return err
+			}
+		}
+		booking.UpdatedAt = time.Now()
+		if err := repo.UpdateBooking(booking); err != nil {
+			return err
+		}
+
+		override.NewIsPaid = booking.IsPaid
+		override.NewAmountPaid = booking.AmountPaid
+		return repo.CreatePaymentOverride(override)
 	})
 }
+
+// settledAmount is what the payment gateway collected for a booking.
+func settledAmount(repo repository.Repository, bookingID string) (float64, error) {
+	intents, err := repo.GetPaymentIntentsByBookingID(bookingID)
+	if err != nil {
+		return 0, err
+	}
+	var settled float64
+	for _, intent := range intents {
+		if intent.Status == models.PaymentPaid {
+			settled += intent.AmountPaid
+		}
+	}
+	return settled, nil
+}
+
+// FinancialSummary totals what confirmed players owe and have paid for a match.
+func (s *BookingService) FinancialSummary(matchID string) (*models.MatchFinancialSummary, error) {
+	bookings, err := s.Repo.GetBookingsByMatchID(matchID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.MatchFinancialSummary{
+		MatchID:    matchID,
+		ByPosition: make(map[models.Position]models.PositionFinance),
+	}
+	for _, b := range bookings {
+		if b.Status != models.StatusConfirmed {
+			continue
+		}
+		summary.ConfirmedCount++
+		if b.IsPaid {
+			summary.PaidCount++
+		}
+		summary.ExpectedTotal += b.Amount
+		summary.CollectedTotal += b.AmountPaid
+		summary.OutstandingTotal += b.Outstanding()
+
+		pos := summary.ByPosition[b.Position]
+		pos.Bookings++
+		pos.Expected += b.Amount
+		pos.Collected += b.AmountPaid
+		pos.Outstanding += b.Outstanding()
+		summary.ByPosition[b.Position] = pos
+	}
+	return summary, nil
+}
+
+// takenCounts returns the spots held per position and in total: confirmed
+// players plus open waitlist offers.
+func takenCounts(bookings []models.Booking) (map[models.Position]int, int) {
+	perPosition := make(map[models.Position]int)
+	total := 0
+	for _, b := range bookings {
+		if b.Status == models.StatusConfirmed || b.Status == models.StatusOffered {
+			perPosition[b.Position]++
+			total++
+		}
+	}
+	return perPosition, total
+}
+
+// hasRoom reports whether one more player fits the position quota, the
+// match-wide MaxPlayers cap (0 = no cap) and the room left in the published
+// lineup (LineupCap, 0 = no cap).
+func hasRoom(match *models.Match, slot *PositionSlot, positionTaken, totalTaken int) bool {
+	if positionTaken >= slot.Quota {
+		return false
+	}
+	if match.MaxPlayers > 0 && totalTaken >= match.MaxPlayers {
+		return false
+	}
+	if match.LineupCap > 0 && totalTaken >= match.LineupCap {
+		return false
+	}
+	return true
+}
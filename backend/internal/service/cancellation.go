@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"time"
+)
+
+// EffectiveCancellationPolicy returns the match's own policy, else its club's.
+// Matches without either can be cancelled freely until kick-off.
+func EffectiveCancellationPolicy(repo repository.Repository, match *models.Match) models.CancellationPolicy {
+	if match.CancellationPolicy != nil {
+		return *match.CancellationPolicy
+	}
+	if match.ClubID != nil {
+		if club, err := repo.GetClubByID(*match.ClubID); err == nil {
+			return club.CancellationPolicy
+		}
+	}
+	return models.CancellationPolicy{}
+}
+
+// evaluateCancellation decides which rule applies to a player cancelling their
+// own booking at now. With needsReplacement set the cancellation only stands
+// if the freed spot can be offered to a waitlisted player, and the full
+// amount is held as the fee until one of them takes it.
+func evaluateCancellation(policy models.CancellationPolicy, booking *models.Booking, match *models.Match, now time.Time) (rule models.CancellationRule, fee float64, needsReplacement bool, err error) {
+	if booking.Status != models.StatusConfirmed {
+		return models.CancelRuleFree, 0, false, nil // Waitlisted, or turning down an offer
+	}
+
+	hoursLeft := match.Date.Sub(now).Hours()
+	if hoursLeft <= 0 {
+		return "", 0, false, fmt.Errorf("match has already started, cancellation is closed")
+	}
+
+	if policy.CancelCutoffHours > 0 && hoursLeft < float64(policy.CancelCutoffHours) {
+		return models.CancelRuleReplacePending, booking.Amount, true, nil
+	}
+	if policy.FreeCancelHours > 0 && hoursLeft < float64(policy.FreeCancelHours) {
+		fee = math.Round(booking.Amount * float64(policy.LateCancelFeePct) / 100)
+		return models.CancelRuleLateFee, fee, false, nil
+	}
+	return models.CancelRuleFree, 0, false, nil
+}
+
+// replaceCutoffCancellation settles the cutoff cancellation whose spot the
+// accepted booking took: preferably one for the same position, else the
+// earliest. Its held fee is dropped and what was paid is refunded.
+// Must run inside the accepting transaction.
+func replaceCutoffCancellation(repo repository.Repository, match *models.Match, accepted *models.Booking, now time.Time) error {
+	bookings, err := repo.GetBookingsByMatchID(match.ID)
+	if err != nil {
+		return err
+	}
+	samePosition := func(b *models.Booking) bool { return b.Position == accepted.Position }
+	var replaced *models.Booking
+	for i := range bookings {
+		b := &bookings[i]
+		if b.Status != models.StatusCancelled || b.CancellationRule != models.CancelRuleReplacePending {
+			continue
+		}
+		if replaced == nil ||
+			samePosition(b) && !samePosition(replaced) ||
+			samePosition(b) == samePosition(replaced) && b.CancelledAt.Before(*replaced.CancelledAt) {
+			replaced = b
+		}
+	}
+	if replaced == nil {
+		return nil
+	}
+
+	replaced.CancellationRule = models.CancelRuleReplaced
+	replaced.CancellationFee = 0
+	replaced.IsPaid = replaced.Outstanding() == 0
+	replaced.UpdatedAt = now
+	if err := repo.UpdateBooking(replaced); err != nil {
+		return err
+	}
+	refund, err := createRefund(repo, replaced, match, "Booking cancelled", false)
+	if err != nil || refund != nil {
+		return err // The refund notice tells the player
+	}
+	return notifyCancellation(repo, replaced, match, match.Title+" - slot Anda sudah diambil pemain lain, pembatalan Anda tanpa biaya.")
+}
+
+// closeUnfilledCutoffs makes the held fee of pending cutoff cancellations
+// final once no waitlist offer is left open for their spots.
+// Must run inside a transaction holding the match lock, after offerWaitlist.
+func closeUnfilledCutoffs(repo repository.Repository, match *models.Match, now time.Time) error {
+	bookings, err := repo.GetBookingsByMatchID(match.ID)
+	if err != nil {
+		return err
+	}
+	for _, b := range bookings {
+		if b.Status == models.StatusOffered {
+			return nil // Someone may still take a spot
+		}
+	}
+	for i := range bookings {
+		b := &bookings[i]
+		if b.Status != models.StatusCancelled || b.CancellationRule != models.CancelRuleReplacePending {
+			continue
+		}
+		b.CancellationRule = models.CancelRuleUnfilled
+		b.UpdatedAt = now
+		if err := repo.UpdateBooking(b); err != nil {
+			return err
+		}
+		body := fmt.Sprintf("%s - tidak ada pemain yang mengambil slot Anda, biaya pembatalan Rp%.0f tetap berlaku.", match.Title, b.CancellationFee)
+		if err := notifyCancellation(repo, b, match, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func notifyCancellation(repo repository.Repository, booking *models.Booking, match *models.Match, body string) error {
+	return repo.CreateNotification(&models.Notification{
+		UserID:    booking.UserID,
+		Title:     "Pembatalan",
+		Body:      body,
+		Type:      "cancellation",
+		RelatedID: match.ID,
+		Read:      false,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"fmt"
+	"reserve_game/internal/models"
+)
+
+// teamPlan is the players to balance, grouped by the match's team
+// constraints. A unit is a keep-together group, a pinned player, or both; it
+// is placed whole and never swapped afterwards. Everyone else is a single.
+type teamPlan struct {
+	units   []teamUnit
+	singles []rosterPlayer
+	apart   map[string][]string // User ID to the users they must not share a team with
+	rules   []models.TeamConstraint
+	names   map[string]string
+	unmet   []models.UnmetConstraint
+}
+
+type teamUnit struct {
+	players []rosterPlayer
+	slot    int // Pinned team, -1 when free
+}
+
+// planTeams groups players by the constraints for n teams. Constraints that
+// can't hold whatever the split (a player who isn't playing, pins to
+// different teams, a pair kept both together and apart) are recorded as
+// unmet straight away.
+func planTeams(players []rosterPlayer, n int, constraints []models.TeamConstraint, names map[string]string) *teamPlan {
+	plan := &teamPlan{apart: make(map[string][]string), names: names}
+
+	index := make(map[string]int, len(players))
+	parent := make([]int, len(players))
+	slot := make([]int, len(players))
+	for i, p := range players {
+		index[p.Booking.UserID] = i
+		parent[i] = i
+		slot[i] = -1
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	// playing reports whether every user of c is in the teams, recording c
+	// as unmet otherwise.
+	playing := func(c models.TeamConstraint, userIDs ...string) bool {
+		for _, id := range userIDs {
+			if _, ok := index[id]; !ok {
+				plan.fail(c, "%s isn't in the teams: not confirmed and paid, or left over", plan.name(id))
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, c := range constraints {
+		if c.Kind != models.ConstraintLock || !playing(c, c.UserID) {
+			continue
+		}
+		if c.Slot >= n {
+			plan.fail(c, "%s is pinned to team %d but only %d teams were generated", plan.name(c.UserID), c.Slot+1, n)
+			continue
+		}
+		slot[index[c.UserID]] = c.Slot
+	}
+	for _, c := range constraints {
+		if c.Kind != models.ConstraintTogether || !playing(c, c.UserID, c.OtherUserID) {
+			continue
+		}
+		a, b := find(index[c.UserID]), find(index[c.OtherUserID])
+		if a == b {
+			continue
+		}
+		if slot[a] >= 0 && slot[b] >= 0 && slot[a] != slot[b] {
+			plan.fail(c, "%s and %s are pinned to different teams", plan.name(c.UserID), plan.name(c.OtherUserID))
+			continue
+		}
+		parent[b] = a
+		if slot[a] < 0 {
+			slot[a] = slot[b]
+		}
+	}
+	for _, c := range constraints {
+		if c.Kind != models.ConstraintApart || !playing(c, c.UserID, c.OtherUserID) {
+			continue
+		}
+		if find(index[c.UserID]) == find(index[c.OtherUserID]) {
+			plan.fail(c, "%s and %s are also kept together", plan.name(c.UserID), plan.name(c.OtherUserID))
+			continue
+		}
+		plan.apart[c.UserID] = append(plan.apart[c.UserID], c.OtherUserID)
+		plan.apart[c.OtherUserID] = append(plan.apart[c.OtherUserID], c.UserID)
+		plan.rules = append(plan.rules, c)
+	}
+
+	size := make(map[int]int)
+	for i := range players {
+		size[find(i)]++
+	}
+	units := make(map[int]int) // Root to index in plan.units
+	for i, p := range players {
+		root := find(i)
+		if size[root] == 1 && slot[root] < 0 {
+			plan.singles = append(plan.singles, p)
+			continue
+		}
+		u, ok := units[root]
+		if !ok {
+			u = len(plan.units)
+			units[root] = u
+			plan.units = append(plan.units, teamUnit{slot: slot[root]})
+		}
+		plan.units[u].players = append(plan.units[u].players, p)
+	}
+	return plan
+}
+
+// clashes counts the players in t that p must be kept apart from, not
+// counting exceptID.
+func (plan *teamPlan) clashes(t *rosterTeam, p rosterPlayer, exceptID string) int {
+	n := 0
+	for _, id := range plan.apart[p.Booking.UserID] {
+		if id != exceptID && t.members[id] {
+			n++
+		}
+	}
+	return n
+}
+
+// checkApart records the keep-apart rules the finished split breaks.
+func (plan *teamPlan) checkApart(teams []rosterTeam) {
+	for _, c := range plan.rules {
+		for i := range teams {
+			if teams[i].members[c.UserID] && teams[i].members[c.OtherUserID] {
+				plan.fail(c, "%s and %s had to share team %d: every other team was ruled out by pins, pairs or size", plan.name(c.UserID), plan.name(c.OtherUserID), i+1)
+			}
+		}
+	}
+}
+
+func (plan *teamPlan) fail(c models.TeamConstraint, format string, args ...interface{}) {
+	plan.unmet = append(plan.unmet, models.UnmetConstraint{
+		ConstraintID: c.ID,
+		Kind:         c.Kind,
+		Reason:       fmt.Sprintf(format, args...),
+	})
+}
+
+func (plan *teamPlan) name(userID string) string {
+	if name := plan.names[userID]; name != "" {
+		return name
+	}
+	return userID
+}
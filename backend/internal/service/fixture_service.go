@@ -0,0 +1,446 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"sort"
+	"time"
+)
+
+var (
+	ErrNoFixtures      = errors.New("no fixtures set up for this match")
+	ErrFixtureNotFound = errors.New("fixture not found")
+)
+
+// FixtureService runs the mini tournament played inside a match between the
+// teams of its published lineup.
+type FixtureService struct {
+	Repo repository.Repository
+}
+
+func NewFixtureService(repo repository.Repository) *FixtureService {
+	return &FixtureService{Repo: repo}
+}
+
+// CreateFixtures schedules the games between the playing teams of the
+// match's published lineup. An earlier schedule is replaced, as long as no
+// result was entered for it yet.
+func (s *FixtureService) CreateFixtures(matchID string, req models.CreateFixturesRequest, createdByID string) (*models.MatchResults, error) {
+	if err := validateFixtures(req); err != nil {
+		return nil, err
+	}
+
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		if _, err := repo.GetMatchByIDLock(matchID); err != nil {
+			return err
+		}
+		existing, err := repo.GetFixtures(matchID)
+		if err != nil {
+			return err
+		}
+		for _, f := range existing {
+			if f.Status == models.FixturePlayed {
+				return errors.New("results were already entered; delete the fixtures first")
+			}
+		}
+		if err := repo.DeleteFixtures(matchID); err != nil {
+			return err
+		}
+
+		published, err := repo.GetTeamsByMatchID(matchID)
+		if err != nil {
+			return err
+		}
+		teams := playingTeams(published)
+		if len(teams) < 2 {
+			return errors.New("publish a lineup with at least 2 teams first")
+		}
+
+		schedule := &models.FixtureSchedule{
+			MatchID:     matchID,
+			LineupID:    teams[0].LineupID,
+			Format:      req.Format,
+			CreatedByID: createdByID,
+			CreatedAt:   time.Now(),
+		}
+		var fixtures []*models.Fixture
+		switch req.Format {
+		case models.FormatRoundRobin:
+			schedule.Legs = max(req.Legs, 1)
+			fixtures = roundRobin(teams, schedule.Legs)
+		case models.FormatWinnerStaysOn:
+			schedule.Games = req.Games
+			fixtures = []*models.Fixture{{Round: 1, HomeTeamID: &teams[0].ID, AwayTeamID: &teams[1].ID}}
+		case models.FormatKnockout:
+			fixtures = knockout(teams)
+		}
+		if err := repo.CreateFixtureSchedule(schedule); err != nil {
+			return err
+		}
+
+		// Later games first, so a knockout game can point at the one its winner goes on to
+		for i, f := range fixtures {
+			f.MatchID = matchID
+			f.Seq = i + 1
+			f.Status = models.FixtureScheduled
+		}
+		for i := len(fixtures) - 1; i >= 0; i-- {
+			if err := repo.CreateFixture(fixtures[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetResults(matchID)
+}
+
+func validateFixtures(req models.CreateFixturesRequest) error {
+	switch req.Format {
+	case models.FormatRoundRobin:
+		if req.Legs < 0 || req.Legs > models.MaxRoundRobinLegs {
+			return fmt.Errorf("legs must be between 1 and %d", models.MaxRoundRobinLegs)
+		}
+	case models.FormatWinnerStaysOn:
+		if req.Games < 0 {
+			return errors.New("games cannot be negative")
+		}
+	case models.FormatKnockout:
+	default:
+		return errors.New("unknown format, use round_robin, winner_stays_on or knockout")
+	}
+	return nil
+}
+
+// roundRobin pairs every team with every other once per leg, by the circle
+// method: one team stays put while the rest rotate around it. With an odd
+// number of teams, one sits out each round. Home and away alternate by round
+// and swap in every other leg.
+func roundRobin(teams []models.Team, legs int) []*models.Fixture {
+	ids := make([]*string, len(teams))
+	for i := range teams {
+		ids[i] = &teams[i].ID
+	}
+	if len(ids)%2 == 1 {
+		ids = append(ids, nil) // Sits out
+	}
+	n := len(ids)
+
+	var fixtures []*models.Fixture
+	round := 0
+	for leg := 0; leg < legs; leg++ {
+		order := append([]*string(nil), ids...)
+		for r := 0; r < n-1; r++ {
+			round++
+			for i := 0; i < n/2; i++ {
+				home, away := order[i], order[n-1-i]
+				if home == nil || away == nil {
+					continue
+				}
+				if (r+leg)%2 == 1 {
+					home, away = away, home
+				}
+				fixtures = append(fixtures, &models.Fixture{Round: round, HomeTeamID: home, AwayTeamID: away})
+			}
+			order = append([]*string{order[0], order[n-1]}, order[1:n-1]...)
+		}
+	}
+	return fixtures
+}
+
+// knockout builds a single-elimination bracket. Teams are seeded by slot and
+// the bracket is padded to a power of two with byes, which go to the top
+// seeds; seeds 1 and 2 can only meet in the final. Games against a bye are
+// left out and the seed starts in the second round. The fixtures come back in
+// play order, linked to the game their winner goes on to.
+func knockout(teams []models.Team) []*models.Fixture {
+	size := 1
+	for size < len(teams) {
+		size *= 2
+	}
+	seeds := []int{0}
+	for len(seeds) < size {
+		next := make([]int, 0, 2*len(seeds))
+		for _, s := range seeds {
+			next = append(next, s, 2*len(seeds)-1-s)
+		}
+		seeds = next
+	}
+
+	var rounds [][]*models.Fixture
+	for games := size / 2; games >= 1; games /= 2 {
+		round := make([]*models.Fixture, games)
+		for k := range round {
+			round[k] = &models.Fixture{Round: len(rounds) + 1}
+		}
+		rounds = append(rounds, round)
+	}
+	for k, f := range rounds[0] {
+		home, away := seeds[2*k], seeds[2*k+1] // away is the lower seed
+		if away >= len(teams) {
+			placeWinner(rounds[1][k/2], k%2 == 0, &teams[home].ID)
+			rounds[0][k] = nil
+			continue
+		}
+		f.HomeTeamID, f.AwayTeamID = &teams[home].ID, &teams[away].ID
+	}
+
+	var fixtures []*models.Fixture
+	for r, round := range rounds {
+		for k, f := range round {
+			if f == nil {
+				continue
+			}
+			if r+1 < len(rounds) {
+				f.NextFixtureID = &rounds[r+1][k/2].ID
+				f.NextAsHome = k%2 == 0
+			}
+			fixtures = append(fixtures, f)
+		}
+	}
+	return fixtures
+}
+
+func placeWinner(f *models.Fixture, home bool, teamID *string) {
+	if home {
+		f.HomeTeamID = teamID
+	} else {
+		f.AwayTeamID = teamID
+	}
+}
+
+// RecordResult enters or corrects a game's score. A knockout winner moves on
+// to the next round, and winner stays on schedules the following game. A
+// result can't be corrected once a game that depends on it was played.
+func (s *FixtureService) RecordResult(matchID, fixtureID string, homeScore, awayScore int) (*models.MatchResults, error) {
+	if homeScore < 0 || awayScore < 0 {
+		return nil, errors.New("scores cannot be negative")
+	}
+
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		if _, err := repo.GetMatchByIDLock(matchID); err != nil {
+			return err
+		}
+		schedule, err := repo.GetFixtureSchedule(matchID)
+		if err != nil {
+			return ErrNoFixtures
+		}
+		fixtures, err := repo.GetFixtures(matchID)
+		if err != nil {
+			return err
+		}
+		idx := -1
+		for i := range fixtures {
+			if fixtures[i].ID == fixtureID {
+				idx = i
+			}
+		}
+		if idx < 0 {
+			return ErrFixtureNotFound
+		}
+		f := &fixtures[idx]
+		if f.HomeTeamID == nil || f.AwayTeamID == nil {
+			return errors.New("this game's teams aren't decided yet")
+		}
+
+		var winner *string
+		if homeScore > awayScore {
+			winner = f.HomeTeamID
+		} else if awayScore > homeScore {
+			winner = f.AwayTeamID
+		}
+
+		switch schedule.Format {
+		case models.FormatKnockout:
+			if winner == nil {
+				return errors.New("knockout games need a winner; enter the score after extra time or penalties")
+			}
+			if f.NextFixtureID != nil {
+				for i := range fixtures {
+					next := &fixtures[i]
+					if next.ID != *f.NextFixtureID {
+						continue
+					}
+					if next.Status == models.FixturePlayed {
+						return errors.New("the next round's game was already played")
+					}
+					placeWinner(next, f.NextAsHome, winner)
+					if err := repo.UpdateFixture(next); err != nil {
+						return err
+					}
+				}
+			}
+		case models.FormatWinnerStaysOn:
+			// The follow-up game depends on this result, so it's rebuilt below
+			for _, later := range fixtures[idx+1:] {
+				if later.Status == models.FixturePlayed {
+					return errors.New("only the latest game can be corrected")
+				}
+				if err := repo.DeleteFixture(later.ID); err != nil {
+					return err
+				}
+			}
+		}
+
+		now := time.Now()
+		f.HomeScore, f.AwayScore = &homeScore, &awayScore
+		f.WinnerTeamID = winner
+		f.Status = models.FixturePlayed
+		f.PlayedAt = &now
+		if err := repo.UpdateFixture(f); err != nil {
+			return err
+		}
+
+		if schedule.Format == models.FormatWinnerStaysOn && (schedule.Games == 0 || f.Seq < schedule.Games) {
+			lineup, err := repo.GetTeamsByLineupID(schedule.LineupID)
+			if err != nil {
+				return err
+			}
+			next := nextChallenge(playingTeams(lineup), fixtures[:idx+1])
+			next.MatchID = matchID
+			next.Seq = f.Seq + 1
+			next.Round = next.Seq
+			next.Status = models.FixtureScheduled
+			return repo.CreateFixture(next)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetResults(matchID)
+}
+
+// nextChallenge is the winner-stays-on game after the last of played: its
+// winner stays on at home, against the team that has waited longest (the
+// lowest slot first among those yet to play). After a draw the challenger stays on
+// and the team that was already on goes off.
+func nextChallenge(teams []models.Team, played []models.Fixture) *models.Fixture {
+	last := played[len(played)-1]
+	stayer := last.AwayTeamID
+	if last.WinnerTeamID != nil {
+		stayer = last.WinnerTeamID
+	}
+
+	lastPlayed := make(map[string]int)
+	for _, f := range played {
+		lastPlayed[*f.HomeTeamID] = f.Seq
+		lastPlayed[*f.AwayTeamID] = f.Seq
+	}
+	var challenger *string
+	for i := range teams {
+		id := &teams[i].ID
+		if *id == *stayer {
+			continue
+		}
+		if challenger == nil || lastPlayed[*id] < lastPlayed[*challenger] {
+			challenger = id
+		}
+	}
+	return &models.Fixture{HomeTeamID: stayer, AwayTeamID: challenger}
+}
+
+func (s *FixtureService) DeleteFixtures(matchID string) error {
+	if _, err := s.Repo.GetFixtureSchedule(matchID); err != nil {
+		return ErrNoFixtures
+	}
+	return s.Repo.DeleteFixtures(matchID)
+}
+
+// GetResults returns the match's games so far with the standings table.
+// Without a schedule, the lists are empty.
+func (s *FixtureService) GetResults(matchID string) (*models.MatchResults, error) {
+	results := &models.MatchResults{Fixtures: []models.Fixture{}, Standings: []models.Standing{}}
+	schedule, err := s.Repo.GetFixtureSchedule(matchID)
+	if err != nil {
+		return results, nil
+	}
+	results.Schedule = schedule
+
+	fixtures, err := s.Repo.GetFixtures(matchID)
+	if err != nil {
+		return nil, err
+	}
+	teams, err := s.Repo.GetTeamsByLineupID(schedule.LineupID)
+	if err != nil {
+		return nil, err
+	}
+	results.Fixtures = fixtures
+	results.Standings = standings(playingTeams(teams), fixtures)
+
+	if schedule.Format == models.FormatKnockout && len(fixtures) > 0 {
+		final := fixtures[len(fixtures)-1]
+		if final.Status == models.FixturePlayed {
+			results.ChampionTeamID = final.WinnerTeamID
+		}
+	}
+	return results, nil
+}
+
+// standings tallies the played games: three points for a win, one for a
+// draw. Teams are ranked by points, then goal difference, then goals scored.
+func standings(teams []models.Team, fixtures []models.Fixture) []models.Standing {
+	table := make([]models.Standing, len(teams))
+	index := make(map[string]int, len(teams))
+	for i, t := range teams {
+		table[i] = models.Standing{TeamID: t.ID, Name: t.Name, Color: t.Color}
+		index[t.ID] = i
+	}
+
+	for _, f := range fixtures {
+		if f.Status != models.FixturePlayed {
+			continue
+		}
+		home, okHome := index[*f.HomeTeamID]
+		away, okAway := index[*f.AwayTeamID]
+		if !okHome || !okAway {
+			continue
+		}
+		tally(&table[home], *f.HomeScore, *f.AwayScore)
+		tally(&table[away], *f.AwayScore, *f.HomeScore)
+	}
+
+	sort.SliceStable(table, func(i, j int) bool {
+		a, b := table[i], table[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.GoalDifference != b.GoalDifference {
+			return a.GoalDifference > b.GoalDifference
+		}
+		return a.GoalsFor > b.GoalsFor
+	})
+	return table
+}
+
+func tally(row *models.Standing, scored, conceded int) {
+	row.Played++
+	row.GoalsFor += scored
+	row.GoalsAgainst += conceded
+	row.GoalDifference = row.GoalsFor - row.GoalsAgainst
+	switch {
+	case scored > conceded:
+		row.Won++
+		row.Points += models.PointsWin
+	case scored == conceded:
+		row.Drawn++
+		row.Points += models.PointsDraw
+	default:
+		row.Lost++
+	}
+}
+
+// playingTeams drops the bench.
+func playingTeams(teams []models.Team) []models.Team {
+	var playing []models.Team
+	for _, t := range teams {
+		if !t.Bench {
+			playing = append(playing, t)
+		}
+	}
+	return playing
+}
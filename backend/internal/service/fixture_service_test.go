@@ -0,0 +1,260 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"reserve_game/internal/models"
+)
+
+func fixtureTeams(n int) []models.Team {
+	teams := make([]models.Team, n)
+	for i := range teams {
+		teams[i] = models.Team{ID: fmt.Sprintf("T%d", i), Name: fmt.Sprintf("Team %d", i), Slot: i}
+	}
+	return teams
+}
+
+func teamID(id *string) string {
+	if id == nil {
+		return "-"
+	}
+	return *id
+}
+
+func TestKnockoutByes(t *testing.T) {
+	type game struct {
+		round      int
+		home, away string // "-" until an earlier game is played
+		next       int    // Index of the game the winner goes on to; -1 for the final
+		nextAsHome bool
+	}
+	for _, tc := range []struct {
+		teams int
+		want  []game
+	}{
+		{2, []game{
+			{1, "T0", "T1", -1, false},
+		}},
+		{3, []game{
+			{1, "T1", "T2", 1, false},
+			{2, "T0", "-", -1, false},
+		}},
+		{5, []game{
+			{1, "T3", "T4", 1, false},
+			{2, "T0", "-", 3, true},
+			{2, "T1", "T2", 3, false},
+			{3, "-", "-", -1, false},
+		}},
+	} {
+		t.Run(fmt.Sprintf("%d teams", tc.teams), func(t *testing.T) {
+			fixtures := knockout(fixtureTeams(tc.teams))
+			if len(fixtures) != len(tc.want) {
+				t.Fatalf("got %d games, want %d", len(fixtures), len(tc.want))
+			}
+			for i, want := range tc.want {
+				f := fixtures[i]
+				got := game{f.Round, teamID(f.HomeTeamID), teamID(f.AwayTeamID), -1, f.NextAsHome}
+				for j, next := range fixtures {
+					if f.NextFixtureID == &next.ID {
+						got.next = j
+					}
+				}
+				if got != want {
+					t.Errorf("game %d = %+v, want %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRoundRobinOddTeams(t *testing.T) {
+	for _, tc := range []struct {
+		teams, legs int
+		want        []string // "round home-away"
+	}{
+		{3, 1, []string{"1 T1-T2", "2 T2-T0", "3 T0-T1"}},
+		{3, 2, []string{"1 T1-T2", "2 T2-T0", "3 T0-T1", "4 T2-T1", "5 T0-T2", "6 T1-T0"}},
+		{5, 1, nil},
+	} {
+		t.Run(fmt.Sprintf("%d teams %d legs", tc.teams, tc.legs), func(t *testing.T) {
+			fixtures := roundRobin(fixtureTeams(tc.teams), tc.legs)
+
+			// Every pair meets once per leg, nobody plays twice in a round and
+			// exactly one team sits out each round.
+			pairs := make(map[[2]string]int)
+			perRound := make(map[int]map[string]bool)
+			for _, f := range fixtures {
+				home, away := teamID(f.HomeTeamID), teamID(f.AwayTeamID)
+				if home > away {
+					home, away = away, home
+				}
+				pairs[[2]string{home, away}]++
+				if perRound[f.Round] == nil {
+					perRound[f.Round] = make(map[string]bool)
+				}
+				for _, id := range []string{home, away} {
+					if perRound[f.Round][id] {
+						t.Errorf("%s plays twice in round %d", id, f.Round)
+					}
+					perRound[f.Round][id] = true
+				}
+			}
+			if want := tc.teams * (tc.teams - 1) / 2; len(pairs) != want {
+				t.Errorf("got %d pairs, want %d", len(pairs), want)
+			}
+			for pair, n := range pairs {
+				if n != tc.legs {
+					t.Errorf("%v meet %d times, want %d", pair, n, tc.legs)
+				}
+			}
+			if len(perRound) != tc.teams*tc.legs {
+				t.Errorf("got %d rounds, want %d", len(perRound), tc.teams*tc.legs)
+			}
+			for round, playing := range perRound {
+				if len(playing) != tc.teams-1 {
+					t.Errorf("round %d: %d teams play, want %d", round, len(playing), tc.teams-1)
+				}
+			}
+
+			if tc.want == nil {
+				return
+			}
+			var got []string
+			for _, f := range fixtures {
+				got = append(got, fmt.Sprintf("%d %s-%s", f.Round, teamID(f.HomeTeamID), teamID(f.AwayTeamID)))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+// playWinnerStaysOn plays a winner-stays-on run with the given scores, from
+// the first game CreateFixtures schedules, and returns the games played.
+func playWinnerStaysOn(teams []models.Team, scores [][2]int) []models.Fixture {
+	next := &models.Fixture{HomeTeamID: &teams[0].ID, AwayTeamID: &teams[1].ID}
+	var played []models.Fixture
+	for i, score := range scores {
+		f := *next
+		f.Seq, f.Round = i+1, i+1
+		f.HomeScore, f.AwayScore = &score[0], &score[1]
+		f.Status = models.FixturePlayed
+		switch {
+		case score[0] > score[1]:
+			f.WinnerTeamID = f.HomeTeamID
+		case score[1] > score[0]:
+			f.WinnerTeamID = f.AwayTeamID
+		}
+		played = append(played, f)
+		next = nextChallenge(teams, played)
+	}
+	return played
+}
+
+func TestWinnerStaysOn(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		teams  int
+		scores [][2]int
+		want   []string // "home-away" per game
+	}{
+		{
+			name:   "winner stays at home",
+			teams:  3,
+			scores: [][2]int{{1, 0}, {2, 0}, {0, 1}},
+			want:   []string{"T0-T1", "T0-T2", "T0-T1"},
+		},
+		{
+			name:   "challenger stays after a draw",
+			teams:  3,
+			scores: [][2]int{{1, 0}, {0, 2}, {1, 1}, {3, 0}, {0, 1}},
+			want:   []string{"T0-T1", "T0-T2", "T2-T1", "T1-T0", "T1-T2"},
+		},
+		{
+			name:   "longest waiting team comes on",
+			teams:  4,
+			scores: [][2]int{{1, 0}, {1, 1}, {0, 1}},
+			want:   []string{"T0-T1", "T0-T2", "T2-T3"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, f := range playWinnerStaysOn(fixtureTeams(tc.teams), tc.scores) {
+				got = append(got, teamID(f.HomeTeamID)+"-"+teamID(f.AwayTeamID))
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestStandings(t *testing.T) {
+	result := func(home, away string, homeScore, awayScore int) models.Fixture {
+		return models.Fixture{
+			HomeTeamID: &home, AwayTeamID: &away,
+			HomeScore: &homeScore, AwayScore: &awayScore,
+			Status: models.FixturePlayed,
+		}
+	}
+	unplayed := func(home, away string) models.Fixture {
+		return models.Fixture{HomeTeamID: &home, AwayTeamID: &away, Status: models.FixtureScheduled}
+	}
+
+	type row struct {
+		team                 string
+		points, gd, goalsFor int
+	}
+	for _, tc := range []struct {
+		name     string
+		fixtures []models.Fixture
+		want     []row
+	}{
+		{
+			name:     "points first",
+			fixtures: playWinnerStaysOn(fixtureTeams(3), [][2]int{{1, 0}, {0, 2}, {1, 1}, {3, 0}, {0, 1}}),
+			want:     []row{{"T2", 7, 3, 4}, {"T1", 4, 1, 4}, {"T0", 3, -4, 1}},
+		},
+		{
+			name: "goal difference breaks a points tie",
+			fixtures: []models.Fixture{
+				result("T0", "T2", 3, 0),
+				result("T1", "T2", 1, 0),
+				result("T0", "T1", 0, 0),
+			},
+			want: []row{{"T0", 4, 3, 3}, {"T1", 4, 1, 1}, {"T2", 0, -4, 0}},
+		},
+		{
+			name: "goals scored break a goal difference tie",
+			fixtures: []models.Fixture{
+				result("T1", "T2", 2, 0),
+				result("T0", "T2", 3, 1),
+				result("T0", "T1", 1, 1),
+			},
+			want: []row{{"T0", 4, 2, 4}, {"T1", 4, 2, 3}, {"T2", 0, -4, 1}},
+		},
+		{
+			name: "unplayed games and slot order on a full tie",
+			fixtures: []models.Fixture{
+				result("T2", "T1", 1, 1),
+				unplayed("T0", "T1"),
+			},
+			want: []row{{"T1", 1, 0, 1}, {"T2", 1, 0, 1}, {"T0", 0, 0, 0}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			table := standings(fixtureTeams(3), tc.fixtures)
+			if len(table) != len(tc.want) {
+				t.Fatalf("got %d rows, want %d", len(table), len(tc.want))
+			}
+			for i, want := range tc.want {
+				r := table[i]
+				if got := (row{r.TeamID, r.Points, r.GoalDifference, r.GoalsFor}); got != want {
+					t.Errorf("row %d = %+v, want %+v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"time"
+)
+
+// TransitionMatch moves the match to the given status if the lifecycle allows
+// it. The caller saves the match.
+func TransitionMatch(match *models.Match, to models.MatchStatus, now time.Time) error {
+	if err := match.Status.ValidateTransition(to); err != nil {
+		return err
+	}
+	if to == models.MatchPublished && match.Status == models.MatchRegistrationClosed {
+		if match.RegistrationClosesAt != nil && !now.Before(*match.RegistrationClosesAt) {
+			return errors.New("move registration_closes_at past now before re-opening registration")
+		}
+		if !now.Before(match.Date) {
+			return errors.New("match has already started")
+		}
+	}
+	match.Status = to
+	match.UpdatedAt = now
+	return nil
+}
+
+// scheduledStatus is the next status the clock moves the match to, or its
+// current status when nothing is due.
+func scheduledStatus(match *models.Match, now time.Time) models.MatchStatus {
+	switch match.Status {
+	case models.MatchPublished:
+		if !now.Before(match.Date) {
+			return models.MatchInProgress
+		}
+		if match.RegistrationClosesAt != nil && !now.Before(*match.RegistrationClosesAt) {
+			return models.MatchRegistrationClosed
+		}
+	case models.MatchRegistrationClosed:
+		if !now.Before(match.Date) {
+			return models.MatchInProgress
+		}
+	case models.MatchInProgress:
+		if !now.Before(match.EndsAt()) {
+			return models.MatchCompleted
+		}
+	}
+	return match.Status
+}
+
+// AdvanceMatches moves every match whose registration close, kick-off or end
+// time has passed along the lifecycle. A match that was missed for a while
+// steps through each status in turn. A match that fails doesn't hold up the
+// rest; the failures come back together, one per match. Returns how many
+// matches changed.
+func (s *BookingService) AdvanceMatches(now time.Time) (int, error) {
+	matchIDs, err := s.Repo.GetMatchIDsDueForAdvance(now)
+	if err != nil {
+		return 0, err
+	}
+
+	advanced := 0
+	var errs []error
+	for _, matchID := range matchIDs {
+		changed := false
+		err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+			match, err := repo.GetMatchByIDLock(matchID)
+			if err != nil {
+				return err
+			}
+			for next := scheduledStatus(match, now); next != match.Status; next = scheduledStatus(match, now) {
+				if err := TransitionMatch(match, next, now); err != nil {
+					return err
+				}
+				changed = true
+			}
+			if !changed {
+				return nil
+			}
+			return repo.UpdateMatch(match)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("match %s: %v", matchID, err))
+			continue
+		}
+		if changed {
+			advanced++
+		}
+	}
+	return advanced, errors.Join(errs...)
+}
+
+// StartLifecycleWorker advances match statuses every interval in the background.
+func (s *BookingService) StartLifecycleWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			n, err := s.AdvanceMatches(now)
+			if err != nil {
+				log.Println("[Lifecycle] Error:", err)
+			}
+			if n > 0 {
+				log.Printf("[Lifecycle] Advanced %d matches", n)
+			}
+		}
+	}()
+}
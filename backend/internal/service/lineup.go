@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"sort"
+	"time"
+)
+
+var ErrLineupNotFound = errors.New("lineup not found")
+
+func (s *TeamService) GetLineups(matchID string) ([]models.TeamLineup, error) {
+	return s.Repo.GetLineups(matchID)
+}
+
+func (s *TeamService) GetLineup(matchID string, version int) (*models.TeamLineup, error) {
+	lineup, err := s.Repo.GetLineup(matchID, version)
+	if err != nil {
+		return nil, ErrLineupNotFound
+	}
+	return lineup, nil
+}
+
+// PublishLineup makes a draft version the one players see, and tells each
+// player in it which team they are on. Members whose booking is no longer
+// confirmed and paid are dropped from it first and listed in Dropped. Players
+// the version left over under the waitlist strategy go back to the waitlist
+// now, and the match is capped at the lineup's size so nobody joins or is
+// offered a spot ahead of them. The version published before it is kept as
+// superseded.
+func (s *TeamService) PublishLineup(match *models.Match, version int) (*models.TeamLineup, error) {
+	var lineup *models.TeamLineup
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		locked, err := repo.GetMatchByIDLock(match.ID)
+		if err != nil {
+			return err
+		}
+		lineup, err = repo.GetLineup(match.ID, version)
+		if err != nil {
+			return ErrLineupNotFound
+		}
+		if lineup.Status != models.LineupDraft {
+			return errors.New("only draft lineups can be published")
+		}
+
+		if err := repo.SupersedeLineups(match.ID); err != nil {
+			return err
+		}
+		now := time.Now()
+		lineup.Status = models.LineupPublished
+		lineup.PublishedAt = &now
+		if err := repo.UpdateLineup(lineup); err != nil {
+			return err
+		}
+
+		active, err := activeBookings(repo, match.ID)
+		if err != nil {
+			return err
+		}
+		for i := range lineup.Teams {
+			team := &lineup.Teams[i]
+			var kept []models.TeamMember
+			for _, m := range team.Members {
+				if active[m.BookingID] {
+					kept = append(kept, m)
+					continue
+				}
+				if err := repo.DeleteTeamMember(m.ID); err != nil {
+					return err
+				}
+				team.Strength -= m.Rating
+				lineup.Dropped = append(lineup.Dropped, m)
+			}
+			if len(kept) < len(team.Members) {
+				team.Members = kept
+				if err := repo.UpdateTeamStrength(team.ID, team.Strength); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := returnToWaitlist(repo, match, lineup); err != nil {
+			return err
+		}
+
+		// Spots freed by dropped members can be refilled; anyone beyond the
+		// lineup waits behind the players it left over.
+		lineupCap := 0
+		if len(lineup.Leftover) > 0 {
+			lineupCap = len(lineup.Dropped)
+			for _, team := range lineup.Teams {
+				lineupCap += len(team.Members)
+			}
+		}
+		if locked.LineupCap != lineupCap {
+			locked.LineupCap = lineupCap
+			if err := repo.UpdateMatch(locked); err != nil {
+				return err
+			}
+		}
+
+		for _, team := range lineup.Teams {
+			body := fmt.Sprintf("%s - susunan tim sudah keluar. Kamu di %s.", match.Title, team.Name)
+			if team.Bench {
+				body = fmt.Sprintf("%s - susunan tim sudah keluar. Kamu di bangku cadangan.", match.Title)
+			}
+			for _, m := range team.Members {
+				if err := notifyLineup(repo, m.UserID, match, body); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lineup, nil
+}
+
+// RollbackLineup copies an earlier version into a new draft, which can then
+// be edited and published like a generated one. Members whose booking is no
+// longer confirmed and paid are left out of the copy and listed in Dropped.
+func (s *TeamService) RollbackLineup(matchID string, version int, createdByID string) (*models.TeamLineup, error) {
+	var draft *models.TeamLineup
+	var dropped []models.TeamMember
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		if _, err := repo.GetMatchByIDLock(matchID); err != nil {
+			return err
+		}
+		source, err := repo.GetLineup(matchID, version)
+		if err != nil {
+			return ErrLineupNotFound
+		}
+		next, err := repo.NextLineupVersion(matchID)
+		if err != nil {
+			return err
+		}
+		active, err := activeBookings(repo, matchID)
+		if err != nil {
+			return err
+		}
+
+		draft = &models.TeamLineup{
+			MatchID:        matchID,
+			Version:        next,
+			Status:         models.LineupDraft,
+			Seed:           source.Seed,
+			RolledBackFrom: &source.Version,
+			Leftover:       source.Leftover,
+			CreatedByID:    createdByID,
+			CreatedAt:      time.Now(),
+		}
+		if err := repo.CreateLineup(draft); err != nil {
+			return err
+		}
+		for _, t := range source.Teams {
+			team := &models.Team{
+				MatchID:  matchID,
+				LineupID: draft.ID,
+				Name:     t.Name,
+				Color:    t.Color,
+				Slot:     t.Slot,
+				Strength: t.Strength,
+				Bench:    t.Bench,
+			}
+			var kept []models.TeamMember
+			for _, m := range t.Members {
+				if active[m.BookingID] {
+					kept = append(kept, m)
+					continue
+				}
+				team.Strength -= m.Rating
+				dropped = append(dropped, m)
+			}
+			if err := repo.CreateTeam(team); err != nil {
+				return err
+			}
+			for _, m := range kept {
+				member := &models.TeamMember{
+					TeamID:    team.ID,
+					UserID:    m.UserID,
+					BookingID: m.BookingID,
+					Position:  m.Position,
+					Rating:    m.Rating,
+				}
+				if err := repo.CreateTeamMember(member); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	draft, err = s.Repo.GetLineup(matchID, draft.Version)
+	if err != nil {
+		return nil, err
+	}
+	draft.Dropped = dropped
+	return draft, nil
+}
+
+// activeBookings returns the IDs of the match's bookings that can be in a
+// team: confirmed and paid.
+func activeBookings(repo repository.Repository, matchID string) (map[string]bool, error) {
+	bookings, err := repo.GetBookingsByMatchID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool, len(bookings))
+	for _, b := range bookings {
+		if b.Status == models.StatusConfirmed && b.IsPaid {
+			active[b.ID] = true
+		}
+	}
+	return active, nil
+}
+
+// DiffLineups lists the players whose team differs between two versions,
+// by name. Teams are matched by name, so renaming a team moves everyone in it.
+func (s *TeamService) DiffLineups(matchID string, from, to int) (*models.LineupDiff, error) {
+	a, err := s.Repo.GetLineup(matchID, from)
+	if err != nil {
+		return nil, ErrLineupNotFound
+	}
+	b, err := s.Repo.GetLineup(matchID, to)
+	if err != nil {
+		return nil, ErrLineupNotFound
+	}
+
+	diff := &models.LineupDiff{From: from, To: to, Changes: []models.LineupChange{}}
+	before, after := lineupTeams(a), lineupTeams(b)
+	names := make(map[string]string)
+	for _, l := range []*models.TeamLineup{a, b} {
+		for _, t := range l.Teams {
+			for _, m := range t.Members {
+				names[m.UserID] = m.User.Name
+			}
+		}
+	}
+	for userID, name := range names {
+		if before[userID] != after[userID] {
+			diff.Changes = append(diff.Changes, models.LineupChange{
+				UserID:   userID,
+				Name:     name,
+				FromTeam: before[userID],
+				ToTeam:   after[userID],
+			})
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool {
+		if diff.Changes[i].Name != diff.Changes[j].Name {
+			return diff.Changes[i].Name < diff.Changes[j].Name
+		}
+		return diff.Changes[i].UserID < diff.Changes[j].UserID
+	})
+	return diff, nil
+}
+
+// lineupTeams maps each player in the lineup to their team's name.
+func lineupTeams(lineup *models.TeamLineup) map[string]string {
+	teams := make(map[string]string)
+	for _, t := range lineup.Teams {
+		for _, m := range t.Members {
+			teams[m.UserID] = t.Name
+		}
+	}
+	return teams
+}
+
+func notifyLineup(repo repository.Repository, userID string, match *models.Match, body string) error {
+	return repo.CreateNotification(&models.Notification{
+		UserID:    userID,
+		Title:     "Susunan Tim",
+		Body:      body,
+		Type:      "lineup",
+		RelatedID: match.ID,
+		Read:      false,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+)
+
+// lineupRepo keeps one match, its bookings and one lineup in memory. Every
+// other method panics through the embedded nil interface.
+type lineupRepo struct {
+	repository.Repository
+	match    models.Match
+	bookings []models.Booking
+	lineup   models.TeamLineup
+}
+
+func (r *lineupRepo) RunTransaction(fn func(repo repository.Repository) error) error {
+	return fn(r)
+}
+
+func (r *lineupRepo) GetMatchByIDLock(id string) (*models.Match, error) {
+	m := r.match
+	return &m, nil
+}
+
+func (r *lineupRepo) UpdateMatch(match *models.Match) error {
+	r.match = *match
+	return nil
+}
+
+func (r *lineupRepo) GetSportByGameType(gameType string) (*models.Sport, error) {
+	return nil, errors.New("record not found")
+}
+
+func (r *lineupRepo) GetLineup(matchID string, version int) (*models.TeamLineup, error) {
+	l := r.lineup
+	l.Teams = append([]models.Team(nil), r.lineup.Teams...)
+	return &l, nil
+}
+
+func (r *lineupRepo) SupersedeLineups(matchID string) error { return nil }
+
+func (r *lineupRepo) UpdateLineup(lineup *models.TeamLineup) error {
+	r.lineup.Status = lineup.Status
+	return nil
+}
+
+func (r *lineupRepo) GetBookingsByMatchID(matchID string) ([]models.Booking, error) {
+	return append([]models.Booking(nil), r.bookings...), nil
+}
+
+func (r *lineupRepo) GetMatchWaitlist(matchID string) ([]models.Booking, error) {
+	var waitlist []models.Booking
+	for _, b := range r.bookings {
+		if b.Status == models.StatusWaitlist {
+			waitlist = append(waitlist, b)
+		}
+	}
+	sort.SliceStable(waitlist, func(i, j int) bool {
+		if waitlist[i].WaitlistOrder != waitlist[j].WaitlistOrder {
+			return waitlist[i].WaitlistOrder < waitlist[j].WaitlistOrder
+		}
+		return waitlist[i].CreatedAt.Before(waitlist[j].CreatedAt)
+	})
+	return waitlist, nil
+}
+
+func (r *lineupRepo) CreateBooking(booking *models.Booking) error {
+	booking.ID = fmt.Sprintf("B%d", len(r.bookings)+1)
+	r.bookings = append(r.bookings, *booking)
+	return nil
+}
+
+func (r *lineupRepo) UpdateBooking(booking *models.Booking) error {
+	for i := range r.bookings {
+		if r.bookings[i].ID == booking.ID {
+			r.bookings[i] = *booking
+			return nil
+		}
+	}
+	return errors.New("booking not found")
+}
+
+func (r *lineupRepo) booking(id string) models.Booking {
+	for _, b := range r.bookings {
+		if b.ID == id {
+			return b
+		}
+	}
+	return models.Booking{}
+}
+
+func (r *lineupRepo) CreateBookingHistory(entry *models.BookingHistory) error    { return nil }
+func (r *lineupRepo) CreateNotification(notification *models.Notification) error { return nil }
+
+// TestPublishLeftoversThenJoin publishes a lineup of two teams of two that
+// left the fifth player over, then has a newcomer join while the quota still
+// has room.
+func TestPublishLeftoversThenJoin(t *testing.T) {
+	now := time.Now()
+	repo := &lineupRepo{
+		match: models.Match{
+			ID:             "match-1",
+			Title:          "Futsal",
+			GameType:       "futsal kampung",
+			Date:           now.Add(48 * time.Hour),
+			Status:         models.MatchPublished,
+			PositionQuotas: models.PositionQuotas{"player": 10},
+		},
+		lineup: models.TeamLineup{ID: "lineup-1", MatchID: "match-1", Version: 1, Status: models.LineupDraft, Leftover: models.BookingIDs{"B5"}},
+	}
+	for i := 1; i <= 5; i++ {
+		repo.bookings = append(repo.bookings, models.Booking{
+			ID: fmt.Sprintf("B%d", i), MatchID: "match-1", UserID: fmt.Sprintf("U%d", i), Position: "player",
+			Status: models.StatusConfirmed, IsPaid: true, CreatedAt: now.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	for slot, ids := range [][]string{{"B1", "B2"}, {"B3", "B4"}} {
+		team := models.Team{ID: fmt.Sprintf("team-%d", slot), LineupID: "lineup-1", Name: fmt.Sprintf("Tim %d", slot+1), Slot: slot}
+		for _, id := range ids {
+			b := repo.booking(id)
+			team.Members = append(team.Members, models.TeamMember{TeamID: team.ID, UserID: b.UserID, BookingID: b.ID})
+		}
+		repo.lineup.Teams = append(repo.lineup.Teams, team)
+	}
+
+	match := repo.match
+	if _, err := NewTeamService(repo).PublishLineup(&match, 1); err != nil {
+		t.Fatal(err)
+	}
+	if b := repo.booking("B5"); b.Status != models.StatusWaitlist || b.WaitlistOrder != 1 {
+		t.Fatalf("leftover B5 is %s at %d, want waitlist at 1", b.Status, b.WaitlistOrder)
+	}
+	if repo.match.LineupCap != 4 {
+		t.Fatalf("lineup cap %d, want 4", repo.match.LineupCap)
+	}
+
+	joined, err := NewBookingService(repo).JoinMatch("U6", "match-1", "player")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if joined.Status != models.StatusWaitlist || joined.WaitlistOrder != 2 {
+		t.Errorf("newcomer is %s at %d, want waitlist at 2 behind the leftover", joined.Status, joined.WaitlistOrder)
+	}
+
+	// A player dropping out frees one spot, for the player left over.
+	b1 := repo.booking("B1")
+	b1.Status = models.StatusCancelled
+	repo.UpdateBooking(&b1)
+	offered, err := offerWaitlist(repo, &repo.match, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offered != 1 {
+		t.Errorf("%d offers, want 1", offered)
+	}
+	if b := repo.booking("B5"); b.Status != models.StatusOffered {
+		t.Errorf("leftover B5 is %s, want offered", b.Status)
+	}
+	if b := repo.booking(joined.ID); b.Status != models.StatusWaitlist {
+		t.Errorf("newcomer is %s, want still waitlisted", b.Status)
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"strings"
+)
+
+// SummarizeMatches turns list results into MatchSummary rows with booking
+// counts from one aggregate query. Quotas are each match's resolved
+// positions, so matches without their own quotas show the sport's defaults;
+// the sports are loaded once for the whole list. userID is the caller (""
+// when signed out) and fills MyBooking.
+func SummarizeMatches(repo repository.Repository, matches []models.Match, userID string) ([]models.MatchSummary, error) {
+	if len(matches) == 0 {
+		return []models.MatchSummary{}, nil
+	}
+	sports, err := repo.GetMasterSports()
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]models.MatchSummary, len(matches))
+	ids := make([]string, len(matches))
+	index := make(map[string]*models.MatchSummary, len(matches))
+	for i := range matches {
+		summaries[i] = newMatchSummary(&matches[i])
+		slots, _ := positionSlots(sportForGameType(sports, matches[i].GameType), matches[i].PositionQuotas)
+		for _, slot := range slots {
+			summaries[i].Counts[slot.Position] = models.PositionCounts{Quota: slot.Quota}
+		}
+		ids[i] = matches[i].ID
+		index[matches[i].ID] = &summaries[i]
+	}
+
+	counts, err := repo.CountMatchBookings(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range counts {
+		summary := index[row.MatchID]
+		tally := summary.Counts[row.Position]
+		if row.Status == models.StatusWaitlist {
+			tally.Waitlist += row.Count
+			summary.WaitlistCount += row.Count
+		} else {
+			// Confirmed or holding an offer: either way the spot is taken
+			tally.Confirmed += row.Count
+			summary.ConfirmedCount += row.Count
+		}
+		summary.Counts[row.Position] = tally
+	}
+
+	if userID != "" {
+		mine, err := repo.GetUserMatchBookings(userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range mine {
+			index[b.MatchID].MyBooking = &models.MyBookingSummary{
+				ID:       b.ID,
+				Position: b.Position,
+				Status:   b.Status,
+				IsPaid:   b.IsPaid,
+			}
+		}
+	}
+	return summaries, nil
+}
+
+func newMatchSummary(m *models.Match) models.MatchSummary {
+	summary := models.MatchSummary{
+		ID:                   m.ID,
+		Title:                m.Title,
+		Description:          m.Description,
+		GameType:             m.GameType,
+		ClubID:               m.ClubID,
+		SeriesID:             m.SeriesID,
+		VenueID:              m.VenueID,
+		CourtID:              m.CourtID,
+		Creator:              m.Creator.Public(),
+		Date:                 m.Date,
+		Location:             m.Location,
+		Price:                m.Price,
+		MaxPlayers:           m.MaxPlayers,
+		Status:               m.Status,
+		PositionQuotas:       m.PositionQuotas,
+		PositionPrices:       m.PositionPrices,
+		RegistrationOpensAt:  m.RegistrationOpensAt,
+		RegistrationClosesAt: m.RegistrationClosesAt,
+		DurationMinutes:      m.DurationMinutes,
+		Latitude:             m.Latitude,
+		Longitude:            m.Longitude,
+		DistanceKm:           m.DistanceKm,
+		Counts:               make(map[models.Position]models.PositionCounts, len(m.PositionQuotas)),
+	}
+	if m.ClubID != nil {
+		summary.Club = &models.ClubSummary{ID: m.Club.ID, Name: m.Club.Name, Logo: m.Club.Logo}
+	}
+	return summary
+}
+
+// sportForGameType finds the sport a game type names, by code or display
+// name as GetSportByGameType does; nil for a free-text game type.
+func sportForGameType(sports []models.Sport, gameType string) *models.Sport {
+	for i := range sports {
+		if strings.EqualFold(sports[i].Code, gameType) || strings.EqualFold(sports[i].Name, gameType) {
+			return &sports[i]
+		}
+	}
+	return nil
+}
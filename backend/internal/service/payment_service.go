@@ -0,0 +1,183 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reserve_game/internal/models"
+	"reserve_game/internal/payment"
+	"reserve_game/internal/repository"
+	"time"
+)
+
+type PaymentService struct {
+	Repo     repository.Repository
+	Provider payment.Provider
+}
+
+func NewPaymentService(repo repository.Repository, provider payment.Provider) *PaymentService {
+	return &PaymentService{Repo: repo, Provider: provider}
+}
+
+// CreateIntent starts an online payment for the outstanding balance of the
+// user's booking; for a cancelled booking that is the late-cancel fee still
+// owed, once it is final. A still-valid pending intent for the same amount is
+// reused.
+func (s *PaymentService) CreateIntent(bookingID string, user *models.User) (*models.PaymentIntent, error) {
+	booking, err := s.Repo.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if booking.UserID != user.ID {
+		return nil, errors.New("unauthorized to pay for this booking")
+	}
+	if booking.CancellationRule == models.CancelRuleReplacePending {
+		return nil, errors.New("cancellation is waiting for a replacement; the fee isn't final yet")
+	}
+	outstanding := booking.Outstanding()
+	if outstanding <= 0 {
+		if booking.Status == models.StatusCancelled {
+			return nil, errors.New("booking is cancelled")
+		}
+		return nil, errors.New("booking has nothing outstanding")
+	}
+
+	intents, err := s.Repo.GetPaymentIntentsByBookingID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range intents {
+		existing := &intents[i]
+		if existing.Status == models.PaymentPending && existing.Amount == outstanding && time.Now().Before(existing.ExpiresAt) {
+			return existing, nil
+		}
+	}
+
+	intent := &models.PaymentIntent{
+		BookingID: booking.ID,
+		UserID:    user.ID,
+		Provider:  s.Provider.Name(),
+		Amount:    outstanding,
+		Status:    models.PaymentPending,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.Repo.CreatePaymentIntent(intent); err != nil {
+		return nil, err
+	}
+
+	invoice, err := s.Provider.CreateInvoice(payment.InvoiceRequest{
+		ExternalID:  intent.ID,
+		Amount:      outstanding,
+		Description: fmt.Sprintf("Booking %s", booking.ID),
+		PayerEmail:  user.Email,
+	})
+	if err != nil {
+		intent.Status = models.PaymentFailed
+		intent.UpdatedAt = time.Now()
+		s.Repo.UpdatePaymentIntent(intent)
+		return nil, fmt.Errorf("payment provider error: %v", err)
+	}
+
+	intent.ProviderRef = invoice.ProviderRef
+	intent.PaymentURL = invoice.PaymentURL
+	intent.ExpiresAt = invoice.ExpiresAt
+	intent.UpdatedAt = time.Now()
+	if err := s.Repo.UpdatePaymentIntent(intent); err != nil {
+		return nil, err
+	}
+	return intent, nil
+}
+
+// HandleWebhook verifies a provider webhook and settles the booking when the
+// invoice is paid, never crediting more than the booking costs. A booking
+// cancelled since the invoice was created keeps the whole payment on record
+// and gets back everything above its late-cancel fee. Replayed webhooks for
+// an already settled intent are ignored.
+func (s *PaymentService) HandleWebhook(payload []byte, signature string) (*models.PaymentIntent, error) {
+	event, err := s.Provider.VerifyWebhookSignature(payload, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var intent *models.PaymentIntent
+	err = s.Repo.RunTransaction(func(repo repository.Repository) error {
+		intent, err = repo.GetPaymentIntentByIDLock(event.ExternalID)
+		if err != nil {
+			return fmt.Errorf("payment intent %s not found", event.ExternalID)
+		}
+		if intent.ProviderRef != event.ProviderRef {
+			return errors.New("webhook does not match payment intent")
+		}
+		if intent.Status == models.PaymentPaid {
+			return nil
+		}
+
+		now := time.Now()
+		switch event.Status {
+		case payment.EventPaid:
+			intent.Status = models.PaymentPaid
+			intent.AmountPaid = event.Amount
+			intent.PaidAt = &now
+
+			// Locked so a concurrent override or second webhook can't lose this payment
+			booking, err := repo.GetBookingByIDLock(intent.BookingID)
+			if err != nil {
+				return err
+			}
+			cancelled := booking.Status == models.StatusCancelled
+			if cancelled {
+				booking.AmountPaid += event.Amount
+			} else {
+				booking.AmountPaid = math.Min(booking.AmountPaid+event.Amount, booking.Amount)
+			}
+			booking.IsPaid = booking.Outstanding() == 0
+			booking.UpdatedAt = now
+			if err := repo.UpdateBooking(booking); err != nil {
+				return err
+			}
+			if cancelled {
+				match, err := repo.GetMatchByID(booking.MatchID)
+				if err != nil {
+					return err
+				}
+				if _, err := createRefund(repo, booking, match, "Paid after cancelling", true); err != nil {
+					return err
+				}
+			}
+		case payment.EventExpired:
+			intent.Status = models.PaymentExpired
+		case payment.EventFailed:
+			intent.Status = models.PaymentFailed
+		default:
+			return fmt.Errorf("unknown payment status %q", event.Status)
+		}
+
+		intent.UpdatedAt = now
+		return repo.UpdatePaymentIntent(intent)
+	})
+	return intent, err
+}
+
+// expireIntents closes the booking's pending payments, so a cancelled booking
+// isn't charged the full price through an invoice created before it was
+// cancelled. A late-cancel fee gets an intent of its own. Must run inside the
+// cancelling transaction.
+func expireIntents(repo repository.Repository, bookingID string) error {
+	intents, err := repo.GetPaymentIntentsByBookingID(bookingID)
+	if err != nil {
+		return err
+	}
+	for i := range intents {
+		intent := &intents[i]
+		if intent.Status != models.PaymentPending {
+			continue
+		}
+		intent.Status = models.PaymentExpired
+		intent.UpdatedAt = time.Now()
+		if err := repo.UpdatePaymentIntent(intent); err != nil {
+			return err
+		}
+	}
+	return nil
+}
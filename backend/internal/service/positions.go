@@ -0,0 +1,142 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"sort"
+)
+
+// PositionSlot is a bookable position of a match with its confirmed-player quota.
+type PositionSlot struct {
+	Position models.Position `json:"position"`
+	Name     string          `json:"name"`
+	Quota    int             `json:"quota"`
+}
+
+// ResolvePositions returns the bookable positions of a match in display order.
+// A position must exist in the sport's SportPosition table (when the match's
+// GameType resolves to a sport) and in the match's PositionQuotas (when set).
+// Without quotas the sport's DefaultQuota applies.
+func ResolvePositions(repo repository.Repository, match *models.Match) ([]PositionSlot, error) {
+	sport, err := repo.GetSportByGameType(match.GameType)
+	if err != nil {
+		sport = nil
+	}
+	return positionSlots(sport, match.PositionQuotas)
+}
+
+// positionSlots is ResolvePositions for a sport already loaded; sport is nil
+// for a free-text game type.
+func positionSlots(sport *models.Sport, quotas models.PositionQuotas) ([]PositionSlot, error) {
+	var slots []PositionSlot
+	if sport != nil {
+		for _, p := range sport.Positions {
+			quota := p.DefaultQuota
+			if len(quotas) > 0 {
+				q, ok := quotas[p.Code]
+				if !ok {
+					continue
+				}
+				quota = q
+			}
+			slots = append(slots, PositionSlot{Position: models.Position(p.Code), Name: p.Name, Quota: quota})
+		}
+	} else {
+		// Unknown sport (free-text game type): the match quotas are all we have
+		codes := make([]string, 0, len(quotas))
+		for code := range quotas {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			slots = append(slots, PositionSlot{Position: models.Position(code), Name: code, Quota: quotas[code]})
+		}
+	}
+
+	if len(slots) == 0 {
+		return nil, errors.New("no positions configured for this match")
+	}
+	return slots, nil
+}
+
+// ValidatePositionConfig checks quotas and prices against the positions of the
+// sport named by gameType and returns the quotas to store. Without quotas the
+// sport's default quotas are used.
+func ValidatePositionConfig(repo repository.Repository, gameType string, quotas models.PositionQuotas, prices models.PositionPrices) (models.PositionQuotas, error) {
+	sport, err := repo.GetSportByGameType(gameType)
+	if err != nil {
+		return nil, fmt.Errorf("unknown sport %q", gameType)
+	}
+
+	defaults := make(models.PositionQuotas, len(sport.Positions))
+	for _, p := range sport.Positions {
+		defaults[p.Code] = p.DefaultQuota
+	}
+
+	if len(quotas) == 0 {
+		quotas = make(models.PositionQuotas)
+		for code, q := range defaults {
+			if q > 0 {
+				quotas[code] = q
+			}
+		}
+	}
+
+	total := 0
+	for code, q := range quotas {
+		if _, ok := defaults[code]; !ok {
+			return nil, fmt.Errorf("position %q does not exist for %s", code, sport.Name)
+		}
+		if q < 0 {
+			return nil, fmt.Errorf("quota for %q cannot be negative", code)
+		}
+		total += q
+	}
+	if total == 0 {
+		return nil, errors.New("at least one position needs a quota above 0")
+	}
+
+	for code, price := range prices {
+		if _, ok := quotas[code]; !ok {
+			return nil, fmt.Errorf("price set for %q but the match has no quota for it", code)
+		}
+		if price < 0 {
+			return nil, fmt.Errorf("price for %q cannot be negative", code)
+		}
+	}
+
+	return quotas, nil
+}
+
+// findSlot returns the slot for a position or an error if the match does not offer it.
+func findSlot(slots []PositionSlot, position models.Position) (*PositionSlot, error) {
+	for i := range slots {
+		if slots[i].Position == position {
+			return &slots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("position %q is not available for this match", position)
+}
+
+// BookingPrice is the price for a position: its PositionPrices entry, or the
+// match's base Price when the position has no price of its own.
+func BookingPrice(match *models.Match, position models.Position) float64 {
+	if price, ok := match.PositionPrices[string(position)]; ok {
+		return price
+	}
+	return match.Price
+}
+
+// DefaultPosition picks the position with the largest quota, used when someone
+// is booked without choosing (e.g. the organiser auto-joining their own match).
+func DefaultPosition(slots []PositionSlot) models.Position {
+	best := slots[0]
+	for _, s := range slots[1:] {
+		if s.Quota > best.Quota {
+			best = s
+		}
+	}
+	return best.Position
+}
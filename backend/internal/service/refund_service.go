@@ -0,0 +1,217 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reserve_game/internal/models"
+	"reserve_game/internal/payment"
+	"reserve_game/internal/repository"
+	"strings"
+	"time"
+)
+
+type RefundService struct {
+	Repo     repository.Repository
+	Provider payment.Provider
+}
+
+func NewRefundService(repo repository.Repository, provider payment.Provider) *RefundService {
+	return &RefundService{Repo: repo, Provider: provider}
+}
+
+// NormalizeRefundPolicy validates a match's refund settings and fills in the
+// defaults (full refund, 100%).
+func NormalizeRefundPolicy(policy models.RefundPolicy, percent *int) (models.RefundPolicy, int, error) {
+	switch policy {
+	case "":
+		policy = models.RefundFull
+	case models.RefundFull, models.RefundCredit:
+	case models.RefundPartial:
+		if percent == nil {
+			return "", 0, errors.New("refund_percent is required for a partial refund policy")
+		}
+	default:
+		return "", 0, fmt.Errorf("unknown refund policy %q", policy)
+	}
+
+	pct := 100
+	if policy == models.RefundPartial {
+		pct = *percent
+		if pct < 0 || pct > 100 {
+			return "", 0, errors.New("refund_percent must be between 0 and 100")
+		}
+	}
+	return policy, pct, nil
+}
+
+// createRefund records what a cancelled booking is owed under the match's
+// refund policy, after deducting any late-cancel fee. Money already covered
+// by an earlier refund of the booking is left out, so a payment that arrives
+// after the booking was refunded gets a refund of its own. Nothing is
+// recorded when nothing is refundable. When the organiser cancels
+// (fullRefund) the partial percentage does not apply. Credit refunds go
+// straight into the club wallet.
+// Must run inside the cancelling transaction.
+func createRefund(repo repository.Repository, booking *models.Booking, match *models.Match, reason string, fullRefund bool) (*models.Refund, error) {
+	earlier, err := repo.GetRefundsByBookingID(booking.ID)
+	if err != nil {
+		return nil, err
+	}
+	covered := booking.CancellationFee
+	for _, r := range earlier {
+		covered = math.Max(covered, r.AmountPaid)
+	}
+	refundable := booking.AmountPaid - covered
+	if refundable <= 0 {
+		return nil, nil
+	}
+
+	policy := match.RefundPolicy
+	if policy == "" || (policy == models.RefundCredit && match.ClubID == nil) {
+		policy = models.RefundFull // No club wallet to credit
+	}
+
+	amount := refundable
+	if policy == models.RefundPartial && !fullRefund {
+		amount = math.Round(refundable * float64(match.RefundPercent) / 100)
+	}
+
+	now := time.Now()
+	refund := &models.Refund{
+		BookingID:  booking.ID,
+		MatchID:    match.ID,
+		UserID:     booking.UserID,
+		ClubID:     match.ClubID,
+		Policy:     policy,
+		AmountPaid: booking.AmountPaid,
+		Amount:     amount,
+		Reason:     reason,
+		Status:     models.RefundPending,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if policy == models.RefundCredit || amount <= 0 {
+		refund.Status = models.RefundSettled
+		refund.SettledAt = &now
+	}
+	if err := repo.CreateRefund(refund); err != nil {
+		return nil, err
+	}
+
+	body := fmt.Sprintf("%s - Rp%.0f akan dikembalikan oleh penyelenggara.", match.Title, amount)
+	if policy == models.RefundCredit {
+		if err := repo.CreateWalletTransaction(&models.WalletTransaction{
+			ClubID:      *match.ClubID,
+			UserID:      booking.UserID,
+			Amount:      amount,
+			RefundID:    &refund.ID,
+			Description: "Refund: " + match.Title,
+			CreatedAt:   now,
+		}); err != nil {
+			return nil, err
+		}
+		body = fmt.Sprintf("%s - Rp%.0f telah ditambahkan ke saldo klub Anda.", match.Title, amount)
+	} else if amount <= 0 {
+		body = fmt.Sprintf("%s - pembatalan ini tidak mendapat pengembalian dana.", match.Title)
+	}
+	if err := notifyRefund(repo, refund, body); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// SettleRefund marks a pending refund as paid back. Any part of the booking
+// that was paid online is refunded through the payment provider; the rest is
+// assumed to be returned by the organiser directly (cash / transfer).
+//
+// The refund is claimed as processing and committed before the gateway is
+// called, and the outcome is recorded in a second transaction, so money never
+// moves inside a transaction that could roll back. Each gateway call is keyed
+// by the refund and the payment it returns, so settling a refund left in
+// processing by a failed attempt never pays the player twice.
+func (s *RefundService) SettleRefund(refundID string, actorID string, note string) (*models.Refund, error) {
+	var refund *models.Refund
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		var err error
+		refund, err = repo.GetRefundByIDLock(refundID)
+		if err != nil {
+			return errors.New("refund not found")
+		}
+		switch refund.Status {
+		case models.RefundSettled:
+			return errors.New("refund already settled")
+		case models.RefundPending:
+			refund.Status = models.RefundProcessing
+			refund.UpdatedAt = time.Now()
+			return repo.UpdateRefund(refund)
+		}
+		return nil // Processing: retry the gateway calls below
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	intents, err := s.Repo.GetPaymentIntentsByBookingID(refund.BookingID)
+	if err != nil {
+		return nil, err
+	}
+	remaining := refund.Amount
+	var refs []string
+	for _, intent := range intents {
+		if remaining <= 0 {
+			break
+		}
+		if intent.Status != models.PaymentPaid || intent.Provider != s.Provider.Name() {
+			continue
+		}
+		amount := math.Min(remaining, intent.AmountPaid)
+		result, err := s.Provider.Refund(intent.ProviderRef, amount, refund.Reason, refund.ID+"/"+intent.ID)
+		if err != nil {
+			return nil, fmt.Errorf("payment provider refund failed, settle again to retry: %v", err)
+		}
+		refs = append(refs, result.ProviderRef)
+		remaining -= result.Amount
+	}
+
+	err = s.Repo.RunTransaction(func(repo repository.Repository) error {
+		var err error
+		refund, err = repo.GetRefundByIDLock(refundID)
+		if err != nil {
+			return err
+		}
+		if refund.Status == models.RefundSettled {
+			return nil // A concurrent attempt recorded it first
+		}
+
+		now := time.Now()
+		refund.Status = models.RefundSettled
+		refund.ProviderRef = strings.Join(refs, ",")
+		refund.Note = note
+		refund.SettledByID = &actorID
+		refund.SettledAt = &now
+		refund.UpdatedAt = now
+		if err := repo.UpdateRefund(refund); err != nil {
+			return err
+		}
+
+		match, err := repo.GetMatchByID(refund.MatchID)
+		if err != nil {
+			return err
+		}
+		return notifyRefund(repo, refund, fmt.Sprintf("%s - Rp%.0f telah dikembalikan.", match.Title, refund.Amount))
+	})
+	return refund, err
+}
+
+func notifyRefund(repo repository.Repository, refund *models.Refund, body string) error {
+	return repo.CreateNotification(&models.Notification{
+		UserID:    refund.UserID,
+		Title:     "Pengembalian Dana",
+		Body:      body,
+		Type:      "refund",
+		RelatedID: refund.MatchID,
+		Read:      false,
+		CreatedAt: time.Now(),
+	})
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"time"
+)
+
+type SeriesService struct {
+	Repo     repository.Repository
+	Bookings *BookingService
+}
+
+func NewSeriesService(repo repository.Repository, bookings *BookingService) *SeriesService {
+	return &SeriesService{Repo: repo, Bookings: bookings}
+}
+
+// SeriesUpdateResult reports what happened to the series' future instances.
+type SeriesUpdateResult struct {
+	Series    *models.MatchSeries `json:"series"`
+	Updated   int                 `json:"updated"`   // Unbooked instances given the new template
+	Cancelled int                 `json:"cancelled"` // Unbooked instances no longer on the schedule
+	Skipped   int                 `json:"skipped"`   // Instances left alone because players already booked
+	Generated int                 `json:"generated"`
+}
+
+// ValidateSeries checks the template and recurrence rule and fills in the
+// position quotas and refund defaults, the same way CreateMatch does.
+func ValidateSeries(repo repository.Repository, series *models.MatchSeries, refundPercent *int) error {
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	quotas, err := ValidatePositionConfig(repo, series.GameType, series.PositionQuotas, series.PositionPrices)
+	if err != nil {
+		return err
+	}
+	series.PositionQuotas = quotas
+
+	policy, percent, err := NormalizeRefundPolicy(series.RefundPolicy, refundPercent)
+	if err != nil {
+		return err
+	}
+	series.RefundPolicy = policy
+	series.RefundPercent = percent
+
+	if series.CancellationPolicy != nil {
+		return series.CancellationPolicy.Validate()
+	}
+	return nil
+}
+
+// CreateSeries saves the series and generates its first instances.
+func (s *SeriesService) CreateSeries(series *models.MatchSeries) (int, error) {
+	series.Active = true
+	series.CreatedAt = time.Now()
+	series.UpdatedAt = time.Now()
+	if err := s.Repo.CreateSeries(series); err != nil {
+		return 0, err
+	}
+	return s.GenerateInstances(series.ID, time.Now())
+}
+
+// GenerateInstances creates the series' matches up to its horizon, skipping
+// dates that already have an instance (even a cancelled one) and dates its
+// court is already booked on, then books the subscribers into the new
+// matches. Returns how many matches were created.
+func (s *SeriesService) GenerateInstances(seriesID string, now time.Time) (int, error) {
+	var created []*models.Match
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		series, err := repo.GetSeriesByIDLock(seriesID)
+		if err != nil {
+			return err
+		}
+		if !series.Active {
+			return nil
+		}
+
+		existing, err := repo.GetSeriesMatches(series.ID, now)
+		if err != nil {
+			return err
+		}
+		taken := make(map[int64]bool, len(existing))
+		for _, m := range existing {
+			taken[m.Date.Unix()] = true
+		}
+
+		for _, date := range series.Occurrences(now, series.Horizon(now)) {
+			if taken[date.Unix()] {
+				continue
+			}
+			match := newSeriesMatch(series, date, now)
+			busy, err := courtTaken(repo, series, match)
+			if err != nil {
+				return err
+			}
+			if busy {
+				continue
+			}
+			if err := repo.CreateMatch(match); err != nil {
+				return err
+			}
+			created = append(created, match)
+		}
+		return repo.UpdateSeries(series) // Keeps the exceptions courtTaken added
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(created) > 0 {
+		subs, err := s.Repo.GetSeriesSubscriptions(seriesID)
+		if err != nil {
+			return len(created), err
+		}
+		for _, match := range created {
+			for _, sub := range subs {
+				s.autoBook(match.ID, sub)
+			}
+		}
+	}
+	return len(created), nil
+}
+
+// GenerateAll tops up every active series. A series that fails doesn't stop
+// the rest; the failures come back together, one per series. Returns how
+// many matches were created.
+func (s *SeriesService) GenerateAll(now time.Time) (int, error) {
+	ids, err := s.Repo.GetActiveSeriesIDs()
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	var errs []error
+	for _, id := range ids {
+		n, err := s.GenerateInstances(id, now)
+		total += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("series %s: %v", id, err))
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// StartGenerationWorker keeps every active series generated ahead in the background.
+func (s *SeriesService) StartGenerationWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			n, err := s.GenerateAll(now)
+			if err != nil {
+				log.Println("[Series] Error:", err)
+			}
+			if n > 0 {
+				log.Printf("[Series] Generated %d matches", n)
+			}
+		}
+	}()
+}
+
+// UpdateSeries saves the edited series. With applyToFuture set, future
+// instances nobody has booked yet take the new template, unbooked instances
+// that no longer fall on the schedule or whose court is taken are cancelled,
+// and missing dates are generated. Booked instances are never changed.
+func (s *SeriesService) UpdateSeries(series *models.MatchSeries, applyToFuture bool) (*SeriesUpdateResult, error) {
+	result := &SeriesUpdateResult{Series: series}
+	now := time.Now()
+
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		if _, err := repo.GetSeriesByIDLock(series.ID); err != nil {
+			return err
+		}
+		series.UpdatedAt = now
+		if err := repo.UpdateSeries(series); err != nil {
+			return err
+		}
+		if !applyToFuture {
+			return nil
+		}
+
+		onSchedule := make(map[int64]bool)
+		for _, date := range series.Occurrences(now, series.Horizon(now)) {
+			onSchedule[date.Unix()] = true
+		}
+		err := s.reworkFutureInstances(repo, series, now, func(match *models.Match) error {
+			if !series.Active || !onSchedule[match.Date.Unix()] {
+				result.Cancelled++
+				return cancelSeriesInstance(repo, match, "Series schedule changed", now)
+			}
+			applySeriesTemplate(match, series)
+			busy, err := courtTaken(repo, series, match)
+			if err != nil {
+				return err
+			}
+			if busy {
+				result.Cancelled++
+				return cancelSeriesInstance(repo, match, "Court already booked", now)
+			}
+			match.UpdatedAt = now
+			result.Updated++
+			return repo.UpdateMatch(match)
+		}, &result.Skipped)
+		if err != nil {
+			return err
+		}
+		return repo.UpdateSeries(series) // Keeps the exceptions courtTaken added
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if applyToFuture {
+		result.Generated, err = s.GenerateInstances(series.ID, now)
+	}
+	return result, err
+}
+
+// EndSeries stops generating instances and cancels the unbooked future ones.
+func (s *SeriesService) EndSeries(seriesID string) (*SeriesUpdateResult, error) {
+	result := &SeriesUpdateResult{}
+	now := time.Now()
+
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		series, err := repo.GetSeriesByIDLock(seriesID)
+		if err != nil {
+			return err
+		}
+		if !series.Active {
+			return errors.New("series already ended")
+		}
+		series.Active = false
+		series.EndDate = &now
+		series.UpdatedAt = now
+		if err := repo.UpdateSeries(series); err != nil {
+			return err
+		}
+		result.Series = series
+
+		return s.reworkFutureInstances(repo, series, now, func(match *models.Match) error {
+			result.Cancelled++
+			return cancelSeriesInstance(repo, match, "Series ended", now)
+		}, &result.Skipped)
+	})
+	return result, err
+}
+
+// Subscribe auto-books the player's usual position into every future instance,
+// starting with the ones already generated.
+func (s *SeriesService) Subscribe(seriesID, userID string, position models.Position) (*models.SeriesSubscription, error) {
+	series, err := s.Repo.GetSeriesByID(seriesID)
+	if err != nil {
+		return nil, errors.New("series not found")
+	}
+	if !series.Active {
+		return nil, errors.New("series has ended")
+	}
+	if _, err := s.Repo.GetSeriesSubscription(seriesID, userID); err == nil {
+		return nil, errors.New("already subscribed to this series")
+	}
+
+	slots, err := ResolvePositions(s.Repo, newSeriesMatch(series, series.StartsAt, time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findSlot(slots, position); err != nil {
+		return nil, err
+	}
+
+	sub := &models.SeriesSubscription{
+		SeriesID:  seriesID,
+		UserID:    userID,
+		Position:  position,
+		CreatedAt: time.Now(),
+	}
+	if err := s.Repo.CreateSeriesSubscription(sub); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.Repo.GetSeriesMatches(seriesID, time.Now())
+	if err != nil {
+		return sub, err
+	}
+	for _, match := range matches {
+		if match.Status == models.MatchPublished {
+			s.autoBook(match.ID, *sub)
+		}
+	}
+	return sub, nil
+}
+
+// Unsubscribe stops auto-booking. Bookings already made are kept.
+func (s *SeriesService) Unsubscribe(seriesID, userID string) error {
+	sub, err := s.Repo.GetSeriesSubscription(seriesID, userID)
+	if err != nil {
+		return errors.New("not subscribed to this series")
+	}
+	return s.Repo.DeleteSeriesSubscription(sub.ID)
+}
+
+// autoBook books a subscriber through the normal join rules (quotas,
+// waitlist, registration window). Failures are logged, not returned, so one
+// player can't block generation for the rest.
+func (s *SeriesService) autoBook(matchID string, sub models.SeriesSubscription) {
+	if _, err := s.Bookings.JoinMatch(sub.UserID, matchID, sub.Position); err != nil {
+		log.Printf("[Series] Auto-book of user %s into match %s failed: %v", sub.UserID, matchID, err)
+	}
+}
+
+// reworkFutureInstances calls fn for every upcoming draft or published
+// instance without active bookings and counts the booked ones in skipped.
+func (s *SeriesService) reworkFutureInstances(repo repository.Repository, series *models.MatchSeries, now time.Time, fn func(match *models.Match) error, skipped *int) error {
+	matches, err := repo.GetSeriesMatches(series.ID, now)
+	if err != nil {
+		return err
+	}
+	for i := range matches {
+		match := &matches[i]
+		if match.Status != models.MatchDraft && match.Status != models.MatchPublished {
+			continue
+		}
+		booked, err := repo.CountActiveBookings(match.ID)
+		if err != nil {
+			return err
+		}
+		if booked > 0 {
+			*skipped++
+			continue
+		}
+		if err := fn(match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// courtTaken checks the instance's court for overlapping matches. A taken
+// date is added to the series' exceptions so it isn't tried again, and the
+// organiser is told; removing the exception retries it once the court is
+// free. Other venue problems are only logged.
+func courtTaken(repo repository.Repository, series *models.MatchSeries, match *models.Match) (bool, error) {
+	check, err := CheckVenueSlot(repo, match)
+	if err != nil {
+		return false, err
+	}
+	for _, warning := range check.Warnings {
+		log.Printf("[Series] %s on %s: %s", series.Title, match.Date.Format("2006-01-02"), warning)
+	}
+	if len(check.Conflicts) == 0 {
+		return false, nil
+	}
+
+	day := match.Date.Format("2006-01-02")
+	series.Exceptions = append(series.Exceptions, day)
+	return true, repo.CreateNotification(&models.Notification{
+		UserID:    series.CreatorID,
+		Title:     "Jadwal Rutin",
+		Body:      fmt.Sprintf("%s - lapangan sudah dipakai pertandingan lain pada %s, jadwal tanggal itu dilewati.", series.Title, match.Date.Format("02 Jan 15:04")),
+		Type:      "series",
+		RelatedID: series.ID,
+		Read:      false,
+		CreatedAt: time.Now(),
+	})
+}
+
+func cancelSeriesInstance(repo repository.Repository, match *models.Match, reason string, now time.Time) error {
+	if err := TransitionMatch(match, models.MatchCancelled, now); err != nil {
+		return err
+	}
+	match.CancelReason = reason
+	return repo.UpdateMatch(match)
+}
+
+func newSeriesMatch(series *models.MatchSeries, date time.Time, now time.Time) *models.Match {
+	clubID := series.ClubID
+	seriesID := series.ID
+	match := &models.Match{
+		ClubID:    &clubID,
+		SeriesID:  &seriesID,
+		CreatorID: series.CreatorID,
+		Date:      date,
+		Status:    models.MatchPublished,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applySeriesTemplate(match, series)
+	return match
+}
+
+// applySeriesTemplate copies the series' match template onto an instance.
+func applySeriesTemplate(match *models.Match, series *models.MatchSeries) {
+	match.Title = series.Title
+	match.Description = series.Description
+	match.GameType = series.GameType
+	match.Location = series.Location
+	match.Price = series.Price
+	match.MaxPlayers = series.MaxPlayers
+	match.PositionQuotas = series.PositionQuotas
+	match.PositionPrices = series.PositionPrices
+	match.RefundPolicy = series.RefundPolicy
+	match.RefundPercent = series.RefundPercent
+	match.CancellationPolicy = series.CancellationPolicy
+	match.WaitlistOfferMinutes = series.WaitlistOfferMinutes
+	match.DurationMinutes = series.DurationMinutes
+	match.VenueID = series.VenueID
+	match.CourtID = series.CourtID
+	if series.Latitude != nil && series.Longitude != nil {
+		match.Latitude = series.Latitude
+		match.Longitude = series.Longitude
+	}
+}
@@ -2,9 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
+	"reserve_game/internal/authz"
 	"reserve_game/internal/models"
 	"reserve_game/internal/repository"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -20,101 +24,302 @@ func (s *TeamService) GetTeams(matchID string) ([]models.Team, error) {
 	return s.Repo.GetTeamsByMatchID(matchID)
 }
 
-func (s *TeamService) GenerateTeams(matchID string) ([]models.Team, error) {
-	var teams []models.Team
+// GenerateTeams adds a draft lineup version with a skill-balanced split of
+// the match's confirmed, paid players, laid out by setup (falling back to the club's
+// team setup) and honouring the match's team constraints where it can; the
+// ones it can't are listed in the split with the reason. A nil seed picks a
+// random one; the seed used is returned so the same split can be generated
+// again. Players see nothing until the draft is published, and under the
+// waitlist strategy nobody is sent back to the waitlist before then.
+func (s *TeamService) GenerateTeams(matchID string, seed *int64, setup models.TeamSetup, createdByID string) (*models.TeamSplit, error) {
+	split := &models.TeamSplit{Seed: time.Now().UnixNano()}
+	if seed != nil {
+		split.Seed = *seed
+	}
+
 	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
-		// 1. Clear existing teams
-		if err := repo.DeleteTeamsByMatchID(matchID); err != nil {
+		if _, err := repo.GetMatchByIDLock(matchID); err != nil {
+			return err
+		}
+		match, err := repo.GetMatchByID(matchID)
+		if err != nil {
+			return err
+		}
+		if match.ClubID != nil {
+			setup = setup.Merge(match.Club.TeamSetup)
+		}
+		if err := setup.Validate(); err != nil {
 			return err
 		}
+		teamCount := setup.Count()
 
-		// 2. Fetch bookings
+		// 1. Fetch bookings
 		bookings, err := repo.GetBookingsByMatchID(matchID)
 		if err != nil {
 			return err
 		}
 
-		// 3. Filter eligible (Confirmed + Paid)
-		var gks []models.Booking
-		var players []models.Booking
-
+		// 2. Filter eligible (Confirmed + Paid), first booked first
+		var eligible []models.Booking
 		for _, b := range bookings {
 			if b.Status == models.StatusConfirmed && b.IsPaid {
-				if b.Position == models.PositionGK {
-					gks = append(gks, b)
-				} else {
-					players = append(players, b)
-				}
+				eligible = append(eligible, b)
 			}
 		}
-
-		if len(gks) == 0 && len(players) == 0 {
-			return errors.New("no eligible paid players found")
+		if len(eligible) < teamCount {
+			return fmt.Errorf("need at least %d paid players for %d teams, have %d", teamCount, teamCount, len(eligible))
 		}
+		constraints, err := repo.GetTeamConstraints(matchID)
+		if err != nil {
+			return err
+		}
+		pinned := make(map[string]bool)
+		for _, c := range constraints {
+			if c.Kind == models.ConstraintLock {
+				pinned[c.UserID] = true
+			}
+		}
+		// Pinned players are never left over
+		sort.SliceStable(eligible, func(i, j int) bool {
+			if pinned[eligible[i].UserID] != pinned[eligible[j].UserID] {
+				return pinned[eligible[i].UserID]
+			}
+			return eligible[i].CreatedAt.Before(eligible[j].CreatedAt)
+		})
 
-		// 4. Shuffle
-		rng := rand.New(rand.NewSource(time.Now().UnixNano()))
-		rng.Shuffle(len(gks), func(i, j int) { gks[i], gks[j] = gks[j], gks[i] })
-		rng.Shuffle(len(players), func(i, j int) { players[i], players[j] = players[j], players[i] })
-
-		// 5. Create 3 Teams
-		teamNames := []string{"Team A", "Team B", "Team C"}
-		teamColors := []string{"#ef4444", "#3b82f6", "#10b981"} // Red, Blue, Green
+		// 3. Set aside the latest bookers who don't fit
+		playing, leftover := splitLeftover(eligible, teamCount, setup)
 
-		createdTeams := make([]*models.Team, 3)
-		for i := 0; i < 3; i++ {
-			createdTeams[i] = &models.Team{
-				MatchID: matchID,
-				Name:    teamNames[i],
-				Color:   teamColors[i],
-			}
-			if err := repo.CreateTeam(createdTeams[i]); err != nil {
-				return err
+		// 4. Open the next version
+		version, err := repo.NextLineupVersion(matchID)
+		if err != nil {
+			return err
+		}
+		split.Lineup = models.TeamLineup{
+			MatchID:     matchID,
+			Version:     version,
+			Status:      models.LineupDraft,
+			Seed:        &split.Seed,
+			CreatedByID: createdByID,
+			CreatedAt:   time.Now(),
+		}
+		if setup.Strategy() == models.LeftoverWaitlist {
+			for _, b := range leftover {
+				split.Lineup.Leftover = append(split.Lineup.Leftover, b.ID)
 			}
 		}
+		if err := repo.CreateLineup(&split.Lineup); err != nil {
+			return err
+		}
+
+		// 5. Balance by skill within the constraints
+		players, err := ratePlayers(repo, match, playing)
+		if err != nil {
+			return err
+		}
+		names := make(map[string]string)
+		for _, b := range match.Bookings {
+			names[b.UserID] = b.User.Name
+		}
+		plan := planTeams(players, teamCount, constraints, names)
+		rng := rand.New(rand.NewSource(split.Seed))
+		balanced := balanceTeams(plan, teamCount, rng)
+		split.StrengthGap = strengthGap(balanced)
+		split.Unmet = plan.unmet
 
-		// 6. Assign GKs
-		for i, gk := range gks {
-			teamIndex := i % 3
-			member := &models.TeamMember{
-				TeamID:    createdTeams[teamIndex].ID,
-				UserID:    gk.UserID,
-				BookingID: gk.ID,
+		// 6. Create the teams, then the bench
+		for i, roster := range balanced {
+			team := &models.Team{
+				MatchID:  matchID,
+				LineupID: split.Lineup.ID,
+				Name:     setup.TeamName(i),
+				Color:    setup.TeamColor(i),
+				Slot:     i,
+				Strength: roster.Strength,
 			}
-			if err := repo.CreateTeamMember(member); err != nil {
+			if err := createTeam(repo, team, roster.Players); err != nil {
 				return err
 			}
 		}
-
-		// 7. Assign Players
-		for i, player := range players {
-			teamIndex := i % 3
-			member := &models.TeamMember{
-				TeamID:    createdTeams[teamIndex].ID,
-				UserID:    player.UserID,
-				BookingID: player.ID,
+		if setup.Strategy() == models.LeftoverBench && len(leftover) > 0 {
+			bench, err := ratePlayers(repo, match, leftover)
+			if err != nil {
+				return err
+			}
+			team := &models.Team{
+				MatchID:  matchID,
+				LineupID: split.Lineup.ID,
+				Name:     models.BenchTeamName,
+				Color:    setup.BenchColor(),
+				Slot:     teamCount,
+				Bench:    true,
+			}
+			for _, p := range bench {
+				team.Strength += p.Rating
 			}
-			if err := repo.CreateTeamMember(member); err != nil {
+			if err := createTeam(repo, team, bench); err != nil {
 				return err
 			}
 		}
-
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Re-fetch with members
-	if err == nil {
-		teams, err = s.Repo.GetTeamsByMatchID(matchID)
+	lineup, err := s.Repo.GetLineup(matchID, split.Lineup.Version)
+	if err != nil {
+		return nil, err
+	}
+	split.Teams = lineup.Teams
+	return split, nil
+}
+
+// splitLeftover returns the bookings that play and the ones left over.
+// Bookings are in booking order, so the latest bookers are left over. With
+// a team size, everyone beyond TeamCount × TeamSize is left over; without
+// one, the remainder of an even split is. The uneven strategy keeps everyone.
+func splitLeftover(bookings []models.Booking, teamCount int, setup models.TeamSetup) ([]models.Booking, []models.Booking) {
+	if setup.Strategy() == models.LeftoverUneven {
+		return bookings, nil
+	}
+	playing := len(bookings) - len(bookings)%teamCount
+	if setup.TeamSize > 0 {
+		playing = min(len(bookings), teamCount*setup.TeamSize)
+	}
+	return bookings[:playing], bookings[playing:]
+}
+
+// returnToWaitlist puts the lineup's left-over players at the front of
+// their position's waitlist: they booked before anyone already waiting.
+// Bookings that are no longer confirmed are skipped. What they paid stays on
+// the booking for when a spot opens up, and is refunded in full if they
+// leave the waitlist instead. Must run inside the publishing transaction.
+func returnToWaitlist(repo repository.Repository, match *models.Match, lineup *models.TeamLineup) error {
+	if len(lineup.Leftover) == 0 {
+		return nil
+	}
+	all, err := repo.GetBookingsByMatchID(match.ID)
+	if err != nil {
+		return err
+	}
+	isLeftover := make(map[string]bool, len(lineup.Leftover))
+	for _, id := range lineup.Leftover {
+		isLeftover[id] = true
+	}
+	var leftover []*models.Booking
+	ahead := make(map[models.Position]int)
+	for i := range all {
+		if b := &all[i]; isLeftover[b.ID] && b.Status == models.StatusConfirmed {
+			leftover = append(leftover, b)
+			ahead[b.Position]++
+		}
+	}
+	sort.SliceStable(leftover, func(i, j int) bool {
+		return leftover[i].CreatedAt.Before(leftover[j].CreatedAt)
+	})
+
+	for i := range all {
+		b := &all[i]
+		if b.Status != models.StatusWaitlist && b.Status != models.StatusOffered {
+			continue
+		}
+		if n := ahead[b.Position]; n > 0 {
+			b.WaitlistOrder += n
+			if err := repo.UpdateBooking(b); err != nil {
+				return err
+			}
+		}
+	}
+
+	now := time.Now()
+	order := make(map[models.Position]int)
+	for _, b := range leftover {
+		order[b.Position]++
+		b.Status = models.StatusWaitlist
+		b.WaitlistOrder = order[b.Position]
+		b.UpdatedAt = now
+		if err := repo.UpdateBooking(b); err != nil {
+			return err
+		}
+		if err := recordHistory(repo, b, models.EventWaitlisted, models.StatusConfirmed, "Not picked for a team"); err != nil {
+			return err
+		}
+		body := fmt.Sprintf("%s - susunan tim sudah keluar dan kamu belum kebagian tim. Kamu kembali di urutan depan daftar tunggu.", match.Title)
+		if b.AmountPaid > 0 {
+			body += fmt.Sprintf(" Pembayaran Rp%.0f tetap tersimpan untuk slotmu, dan dikembalikan penuh jika kamu batal.", b.AmountPaid)
+		}
+		if err := notifyLineup(repo, b.UserID, match, body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createTeam(repo repository.Repository, team *models.Team, players []rosterPlayer) error {
+	if err := repo.CreateTeam(team); err != nil {
+		return err
+	}
+	for _, p := range players {
+		member := &models.TeamMember{
+			TeamID:    team.ID,
+			UserID:    p.Booking.UserID,
+			BookingID: p.Booking.ID,
+			Position:  p.Booking.Position,
+			Rating:    p.Rating,
+		}
+		if err := repo.CreateTeamMember(member); err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return teams, err
+// RatingSport is the key skill ratings are stored under for a game type: the
+// sport code when it names a known sport.
+func RatingSport(repo repository.Repository, gameType string) string {
+	if sport, err := repo.GetSportByGameType(gameType); err == nil {
+		return sport.Code
+	}
+	return strings.ToLower(strings.TrimSpace(gameType))
+}
+
+// ratePlayers pairs bookings with their players' ratings for the match's
+// sport in its rating scope. Unrated players get DefaultSkillRating.
+func ratePlayers(repo repository.Repository, match *models.Match, bookings []models.Booking) ([]rosterPlayer, error) {
+	userIDs := make([]string, len(bookings))
+	for i, b := range bookings {
+		userIDs[i] = b.UserID
+	}
+	ratings, err := repo.GetPlayerRatings(models.MatchRatingScope(match), RatingSport(repo, match.GameType), userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[string]float64, len(ratings))
+	for _, r := range ratings {
+		byUser[r.UserID] = r.Rating
+	}
+
+	players := make([]rosterPlayer, len(bookings))
+	for i, b := range bookings {
+		rating, ok := byUser[b.UserID]
+		if !ok {
+			rating = models.DefaultSkillRating
+		}
+		players[i] = rosterPlayer{Booking: b, Rating: rating}
+	}
+	return players, nil
 }
 
 func (s *TeamService) UpdateTeamMember(memberID string, newTeamID string) error {
 	return s.Repo.UpdateTeamMember(memberID, newTeamID)
 }
 
-func (s *TeamService) UpdateTeamMemberSecure(memberID string, newTeamID string, requestingUserID string) error {
+// UpdateTeamMemberSecure moves a member to another team of the match. With
+// lock, the member is also pinned to that team so the move survives
+// regeneration.
+func (s *TeamService) UpdateTeamMemberSecure(memberID string, newTeamID string, lock bool, requestingUser *models.User) error {
 	// 1. Get Member
 	member, err := s.Repo.GetTeamMemberByID(memberID)
 	if err != nil {
@@ -133,7 +338,7 @@ func (s *TeamService) UpdateTeamMemberSecure(memberID string, newTeamID string,
 		return errors.New("match not found")
 	}
 
-	if match.CreatorID != requestingUserID {
+	if !authz.CanManageMatch(requestingUser, match) {
 		return errors.New("unauthorized: only match creator can manage teams")
 	}
 
@@ -142,9 +347,99 @@ func (s *TeamService) UpdateTeamMemberSecure(memberID string, newTeamID string,
 	if err != nil {
 		return errors.New("target team not found")
 	}
-	if newTeam.MatchID != match.ID {
-		return errors.New("target team belongs to a different match")
+	if newTeam.MatchID != match.ID || newTeam.LineupID != oldTeam.LineupID {
+		return errors.New("target team belongs to a different lineup")
+	}
+	lineup, err := s.Repo.GetLineupByID(oldTeam.LineupID)
+	if err != nil {
+		return errors.New("lineup not found")
+	}
+	if lineup.Status != models.LineupDraft {
+		return errors.New("only draft lineups can be edited; roll back to this version for an editable copy")
+	}
+	if !lock {
+		return s.Repo.UpdateTeamMember(memberID, newTeamID)
+	}
+	if newTeam.Bench {
+		return errors.New("players can't be pinned to the bench")
 	}
 
-	return s.Repo.UpdateTeamMember(memberID, newTeamID)
+	return s.Repo.RunTransaction(func(repo repository.Repository) error {
+		if err := repo.UpdateTeamMember(memberID, newTeamID); err != nil {
+			return err
+		}
+		_, err := addConstraint(repo, &models.TeamConstraint{
+			MatchID:     match.ID,
+			Kind:        models.ConstraintLock,
+			UserID:      member.UserID,
+			Slot:        newTeam.Slot,
+			CreatedByID: requestingUser.ID,
+		})
+		return err
+	})
+}
+
+// AddConstraint saves a team constraint for the match. Both players must be
+// booked into it. A new pin replaces the player's earlier pin; a pair that
+// already has a together or apart rule must have it removed first.
+func (s *TeamService) AddConstraint(match *models.Match, constraint *models.TeamConstraint) (*models.TeamConstraint, error) {
+	if err := constraint.Validate(); err != nil {
+		return nil, err
+	}
+	booked := make(map[string]bool)
+	for _, b := range match.Bookings {
+		if b.Status != models.StatusCancelled {
+			booked[b.UserID] = true
+		}
+	}
+	if !booked[constraint.UserID] || (constraint.Kind != models.ConstraintLock && !booked[constraint.OtherUserID]) {
+		return nil, errors.New("both players must be booked into the match")
+	}
+	constraint.MatchID = match.ID
+
+	var saved *models.TeamConstraint
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		var err error
+		saved, err = addConstraint(repo, constraint)
+		return err
+	})
+	return saved, err
+}
+
+func addConstraint(repo repository.Repository, constraint *models.TeamConstraint) (*models.TeamConstraint, error) {
+	existing, err := repo.GetTeamConstraints(constraint.MatchID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range existing {
+		switch {
+		case constraint.Kind == models.ConstraintLock && c.Kind == models.ConstraintLock && c.UserID == constraint.UserID:
+			if err := repo.DeleteTeamConstraint(c.ID); err != nil {
+				return nil, err
+			}
+		case constraint.Kind != models.ConstraintLock && c.Kind != models.ConstraintLock && c.Pairs(*constraint):
+			return nil, fmt.Errorf("these players already have a %s rule; remove it first", c.Kind)
+		}
+	}
+	constraint.CreatedAt = time.Now()
+	if err := repo.CreateTeamConstraint(constraint); err != nil {
+		return nil, err
+	}
+	return constraint, nil
+}
+
+var ErrConstraintNotFound = errors.New("constraint not found")
+
+// RemoveConstraint deletes one of the match's team constraints.
+func (s *TeamService) RemoveConstraint(matchID, constraintID string) error {
+	constraints, err := s.Repo.GetTeamConstraints(matchID)
+	if err != nil {
+		return err
+	}
+	for _, c := range constraints {
+		if c.ID == constraintID {
+			return s.Repo.DeleteTeamConstraint(c.ID)
+		}
+	}
+	return ErrConstraintNotFound
 }
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+)
+
+// ApplyVenue puts the match at the club's venue and court. A venue with a
+// single court books it by default; one with several needs courtID. The
+// match's Location falls back to the venue's name and address, and it takes
+// the venue's coordinates when the venue has them.
+func ApplyVenue(repo repository.Repository, match *models.Match, venueID string, courtID string) (*models.Venue, error) {
+	venue, court, err := resolveCourt(repo, match.ClubID, venueID, courtID)
+	if err != nil {
+		return nil, err
+	}
+
+	match.VenueID = &venue.ID
+	match.CourtID = nil
+	if court != nil {
+		match.CourtID = &court.ID
+	}
+	if match.Location == "" {
+		match.Location = venue.Label()
+	}
+	if venue.Latitude != nil && venue.Longitude != nil {
+		match.Latitude = venue.Latitude
+		match.Longitude = venue.Longitude
+	}
+	return venue, nil
+}
+
+// ApplySeriesVenue puts every instance of the series at the club's venue and
+// court, with the same court and coordinate rules as ApplyVenue.
+func ApplySeriesVenue(repo repository.Repository, series *models.MatchSeries, venueID string, courtID string) error {
+	venue, court, err := resolveCourt(repo, &series.ClubID, venueID, courtID)
+	if err != nil {
+		return err
+	}
+
+	series.VenueID = &venue.ID
+	series.CourtID = nil
+	if court != nil {
+		series.CourtID = &court.ID
+	}
+	if series.Location == "" {
+		series.Location = venue.Label()
+	}
+	series.Latitude, series.Longitude = venue.Latitude, venue.Longitude
+	return nil
+}
+
+// resolveCourt loads the club's venue and picks the court to book there.
+func resolveCourt(repo repository.Repository, clubID *string, venueID string, courtID string) (*models.Venue, *models.Court, error) {
+	venue, err := repo.GetVenueByID(venueID)
+	if err != nil {
+		return nil, nil, errors.New("venue not found")
+	}
+	if clubID == nil || *clubID != venue.ClubID {
+		return nil, nil, errors.New("venue belongs to another club")
+	}
+
+	switch {
+	case courtID != "":
+		for i := range venue.Courts {
+			if venue.Courts[i].ID == courtID {
+				return venue, &venue.Courts[i], nil
+			}
+		}
+		return nil, nil, fmt.Errorf("court %q is not at %s", courtID, venue.Name)
+	case len(venue.Courts) == 1:
+		return venue, &venue.Courts[0], nil
+	case len(venue.Courts) > 1:
+		return nil, nil, fmt.Errorf("court_id is required: %s has %d courts", venue.Name, len(venue.Courts))
+	}
+	return venue, nil, nil
+}
+
+// CheckVenueSlot looks for other matches on the same court during the
+// match's time slot and warns when the venue is closed or the court is too
+// small. Matches without a venue always pass.
+func CheckVenueSlot(repo repository.Repository, match *models.Match) (*models.VenueSlotCheck, error) {
+	check := &models.VenueSlotCheck{}
+	if match.VenueID == nil {
+		return check, nil
+	}
+	venue, err := repo.GetVenueByID(*match.VenueID)
+	if err != nil {
+		return nil, err
+	}
+
+	conflicts, err := repo.FindSlotConflicts(venue.ID, match.CourtID, match.Date, match.EndsAt(), match.ID)
+	if err != nil {
+		return nil, err
+	}
+	check.Conflicts = conflicts
+
+	if !venue.OpeningHours.Covers(match.Date, match.EndsAt()) {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("%s is closed for part of this time slot", venue.Name))
+	}
+	if match.CourtID != nil {
+		for _, court := range venue.Courts {
+			if court.ID == *match.CourtID && court.Capacity > 0 && match.MaxPlayers > court.Capacity {
+				check.Warnings = append(check.Warnings, fmt.Sprintf("%s fits %d players, match allows %d", court.Name, court.Capacity, match.MaxPlayers))
+			}
+		}
+	}
+	return check, nil
+}
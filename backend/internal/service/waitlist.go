@@ -0,0 +1,289 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reserve_game/internal/models"
+	"reserve_game/internal/repository"
+	"time"
+)
+
+// DefaultOfferWindow applies when a match doesn't set WaitlistOfferMinutes.
+const DefaultOfferWindow = 30 * time.Minute
+
+// offerDeadline gives the player the match's offer window, but never past
+// the close of registration or kick-off.
+func offerDeadline(match *models.Match, now time.Time) time.Time {
+	window := DefaultOfferWindow
+	if match.WaitlistOfferMinutes > 0 {
+		window = time.Duration(match.WaitlistOfferMinutes) * time.Minute
+	}
+	deadline := now.Add(window)
+	if match.RegistrationClosesAt != nil && deadline.After(*match.RegistrationClosesAt) {
+		deadline = *match.RegistrationClosesAt
+	}
+	if deadline.After(match.Date) {
+		deadline = match.Date
+	}
+	return deadline
+}
+
+// offerWaitlist offers freed spots to waitlisted bookings, earliest first, while
+// the position quotas, MaxPlayers and the published lineup's LineupCap allow it. An offered booking holds
+// its spot until the player accepts or the offer expires. A player blocked
+// only by the global cap can get an offer when a spot in another position
+// frees up. Returns how many offers were made.
+// Must run inside a transaction holding the match lock.
+func offerWaitlist(repo repository.Repository, match *models.Match, now time.Time) (int, error) {
+	if match.CheckRegistration(now) != nil {
+		return 0, nil // Cancelled, finished or too late to bring anyone in
+	}
+
+	slots, err := ResolvePositions(repo, match)
+	if err != nil {
+		return 0, err
+	}
+
+	bookings, err := repo.GetBookingsByMatchID(match.ID)
+	if err != nil {
+		return 0, err
+	}
+	positionTaken, totalTaken := takenCounts(bookings)
+
+	waitlist, err := repo.GetMatchWaitlist(match.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	offered := 0
+	for i := range waitlist {
+		if match.MaxPlayers > 0 && totalTaken >= match.MaxPlayers {
+			break
+		}
+		if match.LineupCap > 0 && totalTaken >= match.LineupCap {
+			break
+		}
+		next := &waitlist[i]
+		slot, err := findSlot(slots, next.Position)
+		if err != nil {
+			continue // Position removed from the match since they joined
+		}
+		if !hasRoom(match, slot, positionTaken[next.Position], totalTaken) {
+			continue
+		}
+
+		deadline := offerDeadline(match, now)
+		next.Status = models.StatusOffered
+		next.OfferExpiresAt = &deadline
+		next.UpdatedAt = now
+		if err := repo.UpdateBooking(next); err != nil {
+			return 0, err
+		}
+		if err := recordHistory(repo, next, models.EventOffered, models.StatusWaitlist, "Offer expires "+deadline.Format(time.RFC3339)); err != nil {
+			return 0, err
+		}
+		if err := notifyWaitlist(repo, next, match, fmt.Sprintf("%s - ada slot kosong untuk Anda. Konfirmasi sebelum %s.", match.Title, deadline.Format("02 Jan 15:04"))); err != nil {
+			return 0, err
+		}
+		positionTaken[next.Position]++
+		totalTaken++
+		offered++
+	}
+	return offered, nil
+}
+
+// AcceptOffer confirms the player's offered spot, as long as the match is
+// still open for registration.
+func (s *BookingService) AcceptOffer(bookingID string, userID string) (*models.Booking, error) {
+	var booking *models.Booking
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		var err error
+		booking, err = lockOfferedBooking(repo, bookingID, userID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		if now.After(*booking.OfferExpiresAt) {
+			return errors.New("offer has expired")
+		}
+		match, err := repo.GetMatchByID(booking.MatchID)
+		if err != nil {
+			return err
+		}
+		if err := match.CheckRegistration(now); err != nil {
+			return err
+		}
+
+		booking.Status = models.StatusConfirmed
+		booking.WaitlistOrder = 0
+		booking.OfferExpiresAt = nil
+		booking.UpdatedAt = now
+		if err := repo.UpdateBooking(booking); err != nil {
+			return err
+		}
+		if err := recordHistory(repo, booking, models.EventOfferAccepted, models.StatusOffered, ""); err != nil {
+			return err
+		}
+		return replaceCutoffCancellation(repo, match, booking, now)
+	})
+	return booking, err
+}
+
+// DeclineOffer gives the spot up and offers it to the next player in line.
+// With nobody left to offer it to, a cutoff cancellation waiting on the spot
+// keeps its fee.
+func (s *BookingService) DeclineOffer(bookingID string, userID string) (*models.Booking, error) {
+	var booking *models.Booking
+	err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+		var err error
+		booking, err = lockOfferedBooking(repo, bookingID, userID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := closeOffer(repo, booking, models.EventOfferDeclined, models.CancelRuleOfferDeclined, now); err != nil {
+			return err
+		}
+		match, err := repo.GetMatchByID(booking.MatchID)
+		if err != nil {
+			return err
+		}
+		if _, err := offerWaitlist(repo, match, now); err != nil {
+			return err
+		}
+		return closeUnfilledCutoffs(repo, match, now)
+	})
+	return booking, err
+}
+
+// ExpireOffers cancels offers that passed their deadline and moves each spot
+// on to the next waitlisted player. A match that fails doesn't hold up the
+// rest; the failures come back together, one per match. Returns how many
+// offers expired.
+func (s *BookingService) ExpireOffers(now time.Time) (int, error) {
+	matchIDs, err := s.Repo.GetExpiredOfferMatchIDs(now)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	var errs []error
+	for _, matchID := range matchIDs {
+		expired := 0
+		err := s.Repo.RunTransaction(func(repo repository.Repository) error {
+			match, err := repo.GetMatchByIDLock(matchID)
+			if err != nil {
+				return err
+			}
+			if match.Status == models.MatchCancelled || match.Status == models.MatchCompleted {
+				return nil // Ended since the lookup
+			}
+			bookings, err := repo.GetBookingsByMatchID(matchID)
+			if err != nil {
+				return err
+			}
+			for i := range bookings {
+				b := &bookings[i]
+				if b.Status != models.StatusOffered || b.OfferExpiresAt == nil || !b.OfferExpiresAt.Before(now) {
+					continue
+				}
+				if err := closeOffer(repo, b, models.EventOfferExpired, models.CancelRuleOfferExpired, now); err != nil {
+					return err
+				}
+				if err := notifyWaitlist(repo, b, match, match.Title+" - waktu konfirmasi Anda telah habis."); err != nil {
+					return err
+				}
+				expired++
+			}
+			if _, err := offerWaitlist(repo, match, now); err != nil {
+				return err
+			}
+			return closeUnfilledCutoffs(repo, match, now)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("match %s: %v", matchID, err))
+			continue
+		}
+		total += expired
+	}
+	return total, errors.Join(errs...)
+}
+
+// StartOfferExpiryWorker expires unanswered offers every interval in the background.
+func (s *BookingService) StartOfferExpiryWorker(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			n, err := s.ExpireOffers(now)
+			if err != nil {
+				log.Println("[OfferExpiry] Error:", err)
+			}
+			if n > 0 {
+				log.Printf("[OfferExpiry] Expired %d waitlist offers", n)
+			}
+		}
+	}()
+}
+
+// lockOfferedBooking locks the booking's match and returns the caller's pending offer.
+func lockOfferedBooking(repo repository.Repository, bookingID string, userID string) (*models.Booking, error) {
+	booking, err := repo.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, errors.New("booking not found")
+	}
+	if _, err := repo.GetMatchByIDLock(booking.MatchID); err != nil {
+		return nil, err
+	}
+	booking, err = repo.GetBookingByID(bookingID)
+	if err != nil {
+		return nil, err
+	}
+	if booking.UserID != userID {
+		return nil, errors.New("unauthorized to answer this offer")
+	}
+	if booking.Status != models.StatusOffered || booking.OfferExpiresAt == nil {
+		return nil, errors.New("booking has no pending offer")
+	}
+	return booking, nil
+}
+
+// closeOffer takes a declined or expired offer off the match.
+func closeOffer(repo repository.Repository, booking *models.Booking, event models.BookingEvent, rule models.CancellationRule, now time.Time) error {
+	booking.Status = models.StatusCancelled
+	booking.WaitlistOrder = 0
+	booking.OfferExpiresAt = nil
+	booking.CancelledAt = &now
+	booking.CancellationRule = rule
+	booking.CancelReason = string(event)
+	booking.UpdatedAt = now
+	if err := repo.UpdateBooking(booking); err != nil {
+		return err
+	}
+	return recordHistory(repo, booking, event, models.StatusOffered, "")
+}
+
+func recordHistory(repo repository.Repository, booking *models.Booking, event models.BookingEvent, from models.BookingStatus, note string) error {
+	return repo.CreateBookingHistory(&models.BookingHistory{
+		BookingID:  booking.ID,
+		Event:      event,
+		FromStatus: from,
+		ToStatus:   booking.Status,
+		Note:       note,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func notifyWaitlist(repo repository.Repository, booking *models.Booking, match *models.Match, body string) error {
+	return repo.CreateNotification(&models.Notification{
+		UserID:    booking.UserID,
+		Title:     "Daftar Tunggu",
+		Body:      body,
+		Type:      "waitlist_offer",
+		RelatedID: match.ID,
+		Read:      false,
+		CreatedAt: time.Now(),
+	})
+}
@@ -21,7 +21,7 @@ export default function ClubScreen() {
     const [refreshing, setRefreshing] = useState(false);
 
     // Pagination
-    const [page, setPage] = useState(1);
+    const [cursor, setCursor] = useState(''); // next_cursor of the last loaded page
     const [loadingMore, setLoadingMore] = useState(false);
     const [hasMore, setHasMore] = useState(true);
 
@@ -39,33 +39,33 @@ export default function ClubScreen() {
 
     // Filter Change Effect
     useEffect(() => {
-        setPage(1);
+        setCursor('');
         setHasMore(true);
-        fetchClubs(1, false);
+        fetchClubs('', false);
     }, [debouncedSearch, filterType]);
 
-    const fetchClubs = async (pageNum: number, shouldAppend: boolean = false) => {
+    const fetchClubs = async (pageCursor: string, shouldAppend: boolean = false) => {
         try {
-            if (pageNum === 1) setLoadingMore(false);
+            if (!pageCursor) setLoadingMore(false);
 
             const [clubsData, profileData] = await Promise.all([
-                api.getClubs(pageNum, 10, debouncedSearch, filterType),
-                pageNum === 1 ? api.getProfile().catch(() => null) : Promise.resolve(null)
+                api.getClubs(pageCursor, 10, debouncedSearch, filterType),
+                !pageCursor ? api.getProfile().catch(() => null) : Promise.resolve(null)
             ]);
 
             if (profileData) setCurrentUser(profileData);
 
-            if (clubsData.length < 10) setHasMore(false);
-            else setHasMore(true);
+            setCursor(clubsData.next_cursor);
+            setHasMore(clubsData.next_cursor !== '');
 
             if (shouldAppend) {
                 setClubs(prev => {
                     const existingIds = new Set(prev.map(c => c.id));
-                    const newClubs = clubsData.filter(c => !existingIds.has(c.id));
+                    const newClubs = clubsData.items.filter(c => !existingIds.has(c.id));
                     return [...prev, ...newClubs];
                 });
             } else {
-                setClubs(clubsData);
+                setClubs(clubsData.items);
             }
         } catch (e) {
             console.error(e);
@@ -77,25 +77,23 @@ export default function ClubScreen() {
     useFocusEffect(
         useCallback(() => {
             if (clubs.length === 0) {
-                fetchClubs(1, false);
+                fetchClubs('', false);
             }
         }, [])
     );
 
     const onRefresh = async () => {
         setRefreshing(true);
-        setPage(1);
+        setCursor('');
         setHasMore(true);
-        await fetchClubs(1, false);
+        await fetchClubs('', false);
         setRefreshing(false);
     };
 
     const loadMore = () => {
         if (!hasMore || loadingMore || refreshing) return;
         setLoadingMore(true);
-        const nextPage = page + 1;
-        setPage(nextPage);
-        fetchClubs(nextPage, true);
+        fetchClubs(cursor, true);
     };
 
     return (
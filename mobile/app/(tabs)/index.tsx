@@ -5,7 +5,7 @@ import { useRouter, useFocusEffect } from 'expo-router';
 import { SafeAreaView, useSafeAreaInsets } from 'react-native-safe-area-context';
 import { Ionicons } from '@expo/vector-icons';
 import { useState, useCallback } from 'react';
-import { api, Match, Club } from '@/services/api';
+import { api, MatchSummary, Club } from '@/services/api';
 
 // Exact colors from image analysis
 const PRIMARY_GREEN = '#3E8E41';
@@ -17,8 +17,8 @@ export default function HomeScreen() {
   const router = useRouter();
   const insets = useSafeAreaInsets();
 
-  const [matches, setMatches] = useState<Match[]>([]); // "Info Hari Ini" matches
-  const [myMatches, setMyMatches] = useState<Match[]>([]); // "Jadwal Tanding Kamu"
+  const [matches, setMatches] = useState<MatchSummary[]>([]); // "Info Hari Ini" matches
+  const [myMatches, setMyMatches] = useState<MatchSummary[]>([]); // "Jadwal Tanding Kamu"
   const [myClubs, setMyClubs] = useState<Club[]>([]);  // "Club Saya"
   const [currentUser, setCurrentUser] = useState<any>(null);
   const [refreshing, setRefreshing] = useState(false);
@@ -27,16 +27,16 @@ export default function HomeScreen() {
     try {
       // Parallel fetch
       const [matchesData, myMatchesData, clubsData, profileData] = await Promise.all([
-        api.getMatches(1, 10), // Latest matches for "Info Hari Ini"
-        api.getMatches(1, 10, '', 'joined'), // My Joined Matches
-        api.getClubs(1, 5, '', 'joined'), // My Joined Clubs
+        api.getMatches('', 10), // Latest matches for "Info Hari Ini"
+        api.getMatches('', 10, '', 'joined'), // My Joined Matches
+        api.getClubs('', 5, '', 'joined'), // My Joined Clubs
         api.getProfile().catch(() => null)
       ]);
 
       if (profileData) setCurrentUser(profileData);
-      setMatches(matchesData.reverse());
-      setMyMatches(myMatchesData);
-      setMyClubs(clubsData);
+      setMatches(matchesData.items.reverse());
+      setMyMatches(myMatchesData.items);
+      setMyClubs(clubsData.items);
     } catch (e) {
       console.error(e);
     }
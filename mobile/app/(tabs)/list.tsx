@@ -5,14 +5,14 @@ import { useRouter, useFocusEffect } from 'expo-router';
 import { SafeAreaView } from 'react-native-safe-area-context';
 import { Ionicons } from '@expo/vector-icons';
 import { useState, useCallback, useEffect } from 'react';
-import { api, Match } from '@/services/api';
+import { api, MatchSummary } from '@/services/api';
 
 const PRIMARY_GREEN = '#3E8E41';
 const TEXT_DARK = '#1C1C1E';
 
 export default function MatchesScreen() {
     const router = useRouter();
-    const [matches, setMatches] = useState<Match[]>([]);
+    const [matches, setMatches] = useState<MatchSummary[]>([]);
     const [refreshing, setRefreshing] = useState(false);
     const [loading, setLoading] = useState(true);
 
@@ -45,8 +45,8 @@ export default function MatchesScreen() {
     const fetchMatches = async () => {
         try {
             // Fetch matches (default filter checks date >= today)
-            const data = await api.getMatches(1, 20, debouncedSearch, '', '', selectedSport, 'published'); // Force published for public list
-            setMatches(data);
+            const data = await api.getMatches('', 20, debouncedSearch, '', '', selectedSport, 'published'); // Force published for public list
+            setMatches(data.items);
         } catch (e) {
             console.error(e);
         } finally {
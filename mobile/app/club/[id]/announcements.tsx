@@ -28,7 +28,7 @@ export default function AnnouncementListScreen() {
     const loadAnnouncements = useCallback(async () => {
         try {
             const data = await api.getAnnouncementsForOwner(id as string);
-            setAnnouncements(data || []);
+            setAnnouncements(data.items || []);
         } catch (e: any) {
             Alert.alert('Error', e.message);
         } finally {
@@ -5,7 +5,7 @@ import { useLocalSearchParams, useRouter, useFocusEffect } from 'expo-router';
 import { SafeAreaView } from 'react-native-safe-area-context';
 import { Ionicons } from '@expo/vector-icons';
 import { useState, useCallback, useEffect } from 'react';
-import { api, Club, Match } from '@/services/api';
+import { api, Club, MatchSummary } from '@/services/api';
 
 const PRIMARY_GREEN = '#3E8E41';
 const TEXT_DARK = '#1C1C1E';
@@ -14,7 +14,7 @@ export default function ClubDetailScreen() {
     const { id } = useLocalSearchParams();
     const router = useRouter();
     const [club, setClub] = useState<Club | null>(null);
-    const [matches, setMatches] = useState<Match[]>([]);
+    const [matches, setMatches] = useState<MatchSummary[]>([]);
     const [memberCount, setMemberCount] = useState(0);
     const [announcements, setAnnouncements] = useState<any[]>([]);
     const [refreshing, setRefreshing] = useState(false);
@@ -72,7 +72,7 @@ export default function ClubDetailScreen() {
             // Determine effective status
             const [matchesData, myClubs, announcementsData] = await Promise.all([
                 api.getMatches(
-                    1,
+                    '',
                     10,
                     debouncedSearch,
                     'all',
@@ -80,15 +80,15 @@ export default function ClubDetailScreen() {
                     selectedSport === 'all' ? '' : selectedSport,
                     selectedStatus === 'all' ? '' : selectedStatus
                 ),
-                api.getClubs(1, 100, '', 'joined'),
+                api.getClubs('', 100, '', 'joined'),
                 api.getClubAnnouncements(id as string)
             ]);
 
-            setMatches(matchesData);
-            setAnnouncements(announcementsData);
+            setMatches(matchesData.items);
+            setAnnouncements(announcementsData.items);
 
             // Check membership
-            const memberCheck = myClubs.some(c => c.id === (id as string));
+            const memberCheck = myClubs.items.some(c => c.id === (id as string));
             setIsMember(memberCheck);
 
         } catch (e) {
@@ -335,7 +335,7 @@ export default function ClubDetailScreen() {
                             <View style={styles.footer}>
                                 <View style={styles.footerItem}>
                                     <Ionicons name="people-outline" size={16} color="#757575" />
-                                    <Text style={styles.footerText}>{match.bookings ? match.bookings.filter((b: any) => b.status === 'confirmed').length : 0}/{match.max_players}</Text>
+                                    <Text style={styles.footerText}>{match.confirmed_count}/{match.max_players}</Text>
                                 </View>
                                 <Text style={styles.price}>Rp {match.price.toLocaleString('id-ID')}</Text>
                             </View>
@@ -79,14 +79,14 @@ export default function EditMatchScreen() {
 
             // Populate Complex State (Quotas & Prices)
             if (matchData.position_quotas) {
-                const quotas = JSON.parse(matchData.position_quotas);
+                const quotas: any = matchData.position_quotas;
                 const stringQuotas: any = {};
                 Object.keys(quotas).forEach(k => stringQuotas[k] = quotas[k].toString());
                 setPositionQuotas(stringQuotas);
             }
 
             if (matchData.position_prices) {
-                const prices = JSON.parse(matchData.position_prices);
+                const prices: any = matchData.position_prices;
                 const stringPrices: any = {};
                 let customFound = false;
                 Object.keys(prices).forEach(k => {
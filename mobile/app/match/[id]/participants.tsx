@@ -163,7 +163,7 @@ export default function ParticipantListScreen() {
                         )}
                     </View>
                     {!isWaitlist && (
-                        <Text style={styles.emailText}>{item.user?.email}</Text>
+                        <Text style={styles.emailText}>{item.position}</Text>
                     )}
                     {isWaitlist && (
                         <Text style={[styles.emailText, { color: '#E65100' }]}>Antrian #{item.waitlist_order}</Text>
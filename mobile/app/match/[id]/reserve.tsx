@@ -58,7 +58,7 @@ export default function ReserveSlotScreen() {
             const parsedQuotas: { [key: string]: number } = {};
             try {
                 if (matchData.position_quotas) {
-                    const q = JSON.parse(matchData.position_quotas);
+                    const q: any = matchData.position_quotas;
                     // Ensure numbers
                     Object.keys(q).forEach(k => parsedQuotas[k] = parseInt(q[k]));
                 }
@@ -69,7 +69,7 @@ export default function ReserveSlotScreen() {
             const parsedPrices: { [key: string]: number } = {};
             try {
                 if (matchData.position_prices) {
-                    const p = JSON.parse(matchData.position_prices);
+                    const p: any = matchData.position_prices;
                     Object.keys(p).forEach(k => parsedPrices[k] = parseInt(p[k]));
                 }
             } catch (e) { console.error("Price parse error", e); }
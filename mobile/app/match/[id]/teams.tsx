@@ -5,7 +5,7 @@ import { SafeAreaView } from 'react-native-safe-area-context';
 import { Ionicons } from '@expo/vector-icons';
 import { StatusBar } from 'expo-status-bar';
 import { useState, useEffect } from 'react';
-import { api } from '@/services/api';
+import { api, TeamLineup } from '@/services/api';
 
 const PRIMARY_GREEN = '#3E8E41';
 
@@ -17,6 +17,7 @@ export default function TeamManagementScreen() {
     const [teams, setTeams] = useState<any[]>([]);
     const [loading, setLoading] = useState(false);
     const [generating, setGenerating] = useState(false);
+    const [lineup, setLineup] = useState<TeamLineup | null>(null); // Host only: latest version, draft or published
 
     const [isCreator, setIsCreator] = useState(false);
 
@@ -27,8 +28,7 @@ export default function TeamManagementScreen() {
     const loadData = async () => {
         try {
             setLoading(true);
-            const [teamData, matchData, userData] = await Promise.all([
-                api.getTeams(matchID),
+            const [matchData, userData] = await Promise.all([
                 api.getMatch(matchID),
                 api.getProfile()
             ]);
@@ -37,6 +37,17 @@ export default function TeamManagementScreen() {
             const isMatchCreator = matchData.creator?.id === userData.id;
             setIsCreator(isMatchCreator);
 
+            // Host works on the latest version; players only see the published one
+            let teamData: any[] = [];
+            if (isMatchCreator) {
+                const versions = await api.getLineups(matchID);
+                const latest = versions.length > 0 ? await api.getLineup(matchID, versions[0].version) : null;
+                setLineup(latest);
+                teamData = latest?.teams || [];
+            } else {
+                teamData = await api.getTeams(matchID);
+            }
+
             // Allow everyone to view, but only creator can manage
             // if (matchData.creator?.id !== userData.id) {
             //     Alert.alert("Akses Ditolak", "Hanya host yang dapat mengatur team.");
@@ -53,8 +64,21 @@ export default function TeamManagementScreen() {
             setLoading(false);
         }
     };
+    const isDraft = lineup?.status === 'draft';
+
+    const handlePublish = async () => {
+        if (!lineup) return;
+        try {
+            await api.publishLineup(matchID, lineup.version);
+            Alert.alert("Berhasil", "Susunan tim dipublikasikan ke semua pemain");
+            loadData();
+        } catch (e: any) {
+            Alert.alert("Gagal", e.message || "Gagal mempublikasikan tim");
+        }
+    };
+
     const handleMove = (member: any, currentTeamID: string) => {
-        if (!isCreator) return;
+        if (!isCreator || !isDraft) return;
 
         // Show options to move to other teams
         const otherTeams = teams.filter(t => t.id !== currentTeamID);
@@ -119,12 +143,24 @@ export default function TeamManagementScreen() {
                                 <Text style={styles.actionButtonText}>Processing...</Text>
                             ) : (
                                 <Text style={styles.actionButtonText}>
-                                    {teams.length > 0 ? "Reset & Bagi Ulang Team" : "Generate Teams Otomatis"}
+                                    {teams.length > 0 ? "Bagi Ulang (Draft Baru)" : "Generate Teams Otomatis"}
                                 </Text>
                             )}
                         </TouchableOpacity>
 
-                        {teams.length > 0 && (
+                        {lineup && (
+                            <Text style={styles.helperText}>
+                                Versi {lineup.version} · {isDraft ? "Draft, belum terlihat pemain" : "Sudah dipublikasikan"}
+                            </Text>
+                        )}
+
+                        {isDraft && (
+                            <TouchableOpacity style={[styles.actionButton, { backgroundColor: PRIMARY_GREEN, marginTop: 12 }]} onPress={handlePublish}>
+                                <Text style={styles.actionButtonText}>Publikasikan Tim</Text>
+                            </TouchableOpacity>
+                        )}
+
+                        {isDraft && teams.length > 0 && (
                             <Text style={styles.helperText}>Tap pemain untuk memindahkan ke team lain.</Text>
                         )}
                     </View>
@@ -143,7 +179,7 @@ export default function TeamManagementScreen() {
                                     key={member.id}
                                     style={styles.memberRow}
                                     onPress={() => handleMove(member, team.id)}
-                                    disabled={!isCreator}
+                                    disabled={!isCreator || !isDraft}
                                 >
                                     <View style={styles.avatar}>
                                         <Text style={styles.avatarText}>{member.user.name.charAt(0)}</Text>
@@ -151,7 +187,7 @@ export default function TeamManagementScreen() {
                                     <View style={{ flex: 1 }}>
                                         <Text style={styles.memberName}>{member.user.name}</Text>
                                     </View>
-                                    {isCreator && <Ionicons name="swap-horizontal" size={20} color="#BDBDBD" />}
+                                    {isCreator && isDraft && <Ionicons name="swap-horizontal" size={20} color="#BDBDBD" />}
                                 </TouchableOpacity>
                             ))}
                             {(!team.members || team.members.length === 0) && (
@@ -18,8 +18,8 @@ export default function MyClubsScreen() {
     const fetchMyClubs = async () => {
         try {
             // Fetch clubs created by the user
-            const data = await api.getClubs(1, 100, '', 'created');
-            setClubs(data);
+            const data = await api.getClubs('', 100, '', 'created');
+            setClubs(data.items);
         } catch (e) {
             console.error('Failed to fetch my clubs:', e);
         } finally {
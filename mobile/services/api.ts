@@ -23,16 +23,21 @@ const getBaseUrl = () => {
 const API_URL = getBaseUrl();
 
 // Types
+// Other people (match creators, players, team members) only expose id, name
+// and avatar; the rest is only on your own profile (login, getProfile).
 export interface User {
     id: string;
     name: string;
-    email: string;
-    phone: string;
     avatar: string;
-    provider: string;
-    role: string;
+    email?: string;
+    phone?: string;
+    provider?: string;
+    role?: string;
+    has_push_token?: boolean;
 }
 
+export type MatchStatus = 'draft' | 'published' | 'registration_closed' | 'in_progress' | 'completed' | 'cancelled';
+
 export interface Match {
     id: string;
     title: string;
@@ -42,16 +47,235 @@ export interface Match {
     location: string;
     price: number;
     max_players: number;
-    status: string;
+    lineup_cap?: number; // Spots the published lineup has room for once it left players over; 0 = no cap
+    status: MatchStatus;
+    series_id?: string | null; // Generated from a recurring series
+    venue_id?: string | null;
+    court_id?: string | null;
+    venue_warnings?: string[]; // Create / update only: closed hours or court too small, saved anyway
+    venue?: Venue;
+    latitude?: number | null;
+    longitude?: number | null;
+    distance_km?: number; // Only on nearby searches
+    registration_opens_at?: string | null; // null = open once published
+    registration_closes_at?: string | null; // null = open until kick-off
+    duration_minutes?: number;
     reschedule_reason?: string;
-    position_quotas?: string; // JSON string
-    position_prices?: string; // JSON string
+    position_quotas?: { [code: string]: number };
+    position_prices?: { [code: string]: number };
+    refund_policy?: 'full' | 'partial' | 'credit';
+    refund_percent?: number;
+    cancellation_policy?: CancellationPolicy | null; // null = club policy
+    waitlist_offer_minutes?: number; // 0 = server default
     bookings: any[]; // Define Booking type if needed
     creator?: User;
     club_id?: string;
     club?: Club;
 }
 
+// List row from getMatches: booking counts instead of bookings (use getMatch for those)
+export interface PositionCounts {
+    quota: number;
+    confirmed: number; // Includes spots held by an open waitlist offer
+    waitlist: number;
+}
+
+export interface MatchSummary extends Omit<Match, 'bookings' | 'creator' | 'club'> {
+    creator: { id: string; name: string; avatar: string };
+    club: { id: string; name: string; logo: string } | null;
+    counts: { [position: string]: PositionCounts };
+    confirmed_count: number;
+    waitlist_count: number;
+    my_booking: { id: string; position: string; status: string; is_paid: boolean } | null; // null when signed out or not booked
+}
+
+// Paginated lists: pass next_cursor back as `cursor`; it is '' on the last page
+export interface Page<T> {
+    items: T[];
+    next_cursor: string;
+    total: number;
+}
+
+// Nearby search: results within radiusKm (default 10, max 200), nearest first
+export interface NearQuery {
+    lat: number;
+    lng: number;
+    radiusKm?: number;
+}
+
+const nearParams = (near: NearQuery) => {
+    const params: any = { lat: near.lat.toString(), lng: near.lng.toString() };
+    if (near.radiusKm) params.radius_km = near.radiusKm.toString();
+    return params;
+};
+
+// Match search filters; dates are YYYY-MM-DD (inclusive), times HH:MM
+export interface MatchSearch {
+    dateFrom?: string;
+    dateTo?: string;
+    days?: string[]; // 'mon' … 'sun'
+    timeFrom?: string;
+    timeTo?: string;
+    minPrice?: number;
+    maxPrice?: number;
+    hasOpenSlotFor?: string; // Position code; prices then compare that position's price
+    onlyWithSpace?: boolean;
+}
+
+const searchParams = (search: MatchSearch) => {
+    const params: any = {};
+    if (search.dateFrom) params.date_from = search.dateFrom;
+    if (search.dateTo) params.date_to = search.dateTo;
+    if (search.days?.length) params.days = search.days.join(',');
+    if (search.timeFrom) params.time_from = search.timeFrom;
+    if (search.timeTo) params.time_to = search.timeTo;
+    if (search.minPrice !== undefined) params.min_price = search.minPrice.toString();
+    if (search.maxPrice !== undefined) params.max_price = search.maxPrice.toString();
+    if (search.hasOpenSlotFor) params.has_open_slot_for = search.hasOpenSlotFor;
+    if (search.onlyWithSpace) params.only_with_space = 'true';
+    return params;
+};
+
+// Skill rating per sport and club (or organiser, outside a club), 1-10;
+// unrated players count as 5
+export interface PlayerRating {
+    user_id: string;
+    sport: string;
+    club_id: string;
+    organiser_id: string;
+    rating: number;
+}
+
+// What to do with players who don't fit an even split or the team size
+export type LeftoverStrategy = 'uneven' | 'bench' | 'waitlist';
+
+// Every field is optional; blanks fall back to the club's setup, then to 3 teams
+export interface TeamSetup {
+    team_count?: number;
+    team_size?: number; // Players per team; 0 = split everyone
+    names?: string[]; // Defaults to Team A, Team B, …
+    colors?: string[]; // Hex, e.g. #ef4444
+    leftover?: LeftoverStrategy; // Defaults to uneven
+}
+
+export type LineupStatus = 'draft' | 'published' | 'superseded';
+
+// One version of a match's teams; players only see the published one
+export interface TeamLineup {
+    id: string;
+    match_id: string;
+    version: number;
+    status: LineupStatus;
+    seed?: number | null;
+    rolled_back_from?: number | null;
+    leftover?: string[]; // Booking IDs sent back to the waitlist when published
+    published_at?: string | null;
+    created_at: string;
+    teams?: any[]; // Only when fetching a single version
+    dropped?: any[]; // Publish / rollback: members left out, booking no longer confirmed and paid
+}
+
+export interface LineupDiff {
+    from: number;
+    to: number;
+    changes: { user_id: string; name: string; from_team: string; to_team: string }[]; // Empty team = not in that version
+}
+
+export interface TeamSplit {
+    lineup: TeamLineup; // The new draft
+    seed: number; // Send again to reproduce the split
+    strength_gap: number; // Strongest minus weakest team
+    teams: any[]; // Each team has slot, strength and bench; members have position and rating
+    unmet: UnmetConstraint[]; // Constraints that could not all be satisfied
+}
+
+export type FixtureFormat = 'round_robin' | 'winner_stays_on' | 'knockout';
+
+// One game inside a match; teams are null until decided (later knockout rounds)
+export interface Fixture {
+    id: string;
+    seq: number; // Game number
+    round: number;
+    home_team_id: string | null;
+    home_team?: any;
+    away_team_id: string | null;
+    away_team?: any;
+    home_score: number | null;
+    away_score: number | null;
+    winner_team_id: string | null; // null for draws and unplayed games
+    status: 'scheduled' | 'played';
+    played_at?: string | null;
+}
+
+export interface Standing {
+    team_id: string;
+    name: string;
+    color: string;
+    played: number;
+    won: number;
+    drawn: number;
+    lost: number;
+    goals_for: number;
+    goals_against: number;
+    goal_difference: number;
+    points: number; // 3 for a win, 1 for a draw
+}
+
+export interface MatchResults {
+    schedule: { id: string; format: FixtureFormat; legs: number; games: number } | null;
+    fixtures: Fixture[];
+    standings: Standing[];
+    champion_team_id: string | null; // Knockout, once the final is played
+}
+
+export type ConstraintKind = 'lock' | 'together' | 'apart';
+
+export interface TeamConstraint {
+    id: string;
+    match_id: string;
+    kind: ConstraintKind;
+    user_id: string;
+    other_user_id: string; // together and apart
+    slot: number; // lock: 0-based team slot
+}
+
+export interface UnmetConstraint {
+    constraint_id: string;
+    kind: ConstraintKind;
+    reason: string;
+}
+
+export interface Venue {
+    id: string;
+    club_id: string;
+    name: string;
+    address: string;
+    latitude?: number | null; // null when the venue has no pin
+    longitude?: number | null;
+    default_price: number;
+    opening_hours: { [day: string]: { open: string; close: string } }; // mon … sun
+    courts: { id: string; name: string; capacity: number }[];
+}
+
+export interface MatchSeries {
+    id: string;
+    club_id: string;
+    title: string;
+    game_type: string;
+    location: string;
+    price: number;
+    max_players: number;
+    venue_id?: string | null;
+    court_id?: string | null;
+    latitude?: number | null;
+    longitude?: number | null;
+    frequency: 'weekly' | 'biweekly' | 'monthly';
+    starts_at: string; // First kick-off
+    end_date?: string | null;
+    exceptions: string[]; // YYYY-MM-DD; includes dates skipped because the court was taken
+    active: boolean;
+}
+
 export interface Club {
     id: string;
     name: string;
@@ -60,7 +284,18 @@ export interface Club {
     creator_id: string;
     creator?: User;
     social_media?: string; // JSON string
+    cancellation_policy?: CancellationPolicy;
+    team_setup?: TeamSetup; // Default for generating teams
     member_count?: number;
+    latitude?: number | null;
+    longitude?: number | null;
+    distance_km?: number; // Only on nearby searches
+}
+
+export interface CancellationPolicy {
+    free_cancel_hours: number;
+    late_cancel_fee_pct: number;
+    cancel_cutoff_hours: number;
 }
 
 export interface GetClubResponse {
@@ -80,7 +315,16 @@ export interface CreateMatchRequest {
     max_players: number;
     position_quotas?: string; // JSON string
     position_prices?: string; // JSON string
-    status?: string; // 'draft' | 'published'
+    refund_policy?: 'full' | 'partial' | 'credit';
+    refund_percent?: number; // Required for 'partial'
+    status?: 'draft' | 'published';
+    template_id?: string; // Fields left out are taken from the template
+    venue_id?: string; // Fills location and price when left out
+    court_id?: string;
+    allow_conflicts?: boolean; // Save even if the court is already booked (otherwise 409); other venue problems only warn
+    registration_opens_at?: string; // ISO 8601
+    registration_closes_at?: string; // ISO 8601
+    duration_minutes?: number;
 }
 
 export interface PositionMaster {
@@ -96,11 +340,11 @@ export interface SportMaster {
 }
 
 export const api = {
-    async getMatches(page: number = 1, limit: number = 10, search: string = '', filter: string = 'all', clubId: string = '', sport: string = '', status: string = ''): Promise<Match[]> {
+    async getMatches(cursor: string = '', limit: number = 10, search: string = '', filter: string = 'all', clubId: string = '', sport: string = '', status: string = '', near?: NearQuery, filters?: MatchSearch): Promise<Page<MatchSummary>> {
         const token = await getToken();
-        console.log(`[API] getMatches params: page=${page} search=${search} filter=${filter} clubId=${clubId} sport=${sport} status=${status}`);
+        console.log(`[API] getMatches params: cursor=${cursor} search=${search} filter=${filter} clubId=${clubId} sport=${sport} status=${status}`);
         const params: any = {
-            page: page.toString(),
+            cursor,
             limit: limit.toString(),
             search,
             filter
@@ -108,6 +352,8 @@ export const api = {
         if (clubId) params.club_id = clubId;
         if (sport) params.sport = sport;
         if (status) params.status = status;
+        if (near) Object.assign(params, nearParams(near));
+        if (filters) Object.assign(params, searchParams(filters));
 
         const queryParams = new URLSearchParams(params);
         const res = await fetch(`${API_URL}/matches?${queryParams.toString()}`, {
@@ -120,14 +366,15 @@ export const api = {
         return res.json();
     },
 
-    async getClubs(page: number = 1, limit: number = 10, search: string = '', filter: string = ''): Promise<Club[]> {
+    async getClubs(cursor: string = '', limit: number = 10, search: string = '', filter: string = '', near?: NearQuery): Promise<Page<Club>> {
         const token = await getToken();
         const params: any = {
-            page: page.toString(),
+            cursor,
             limit: limit.toString(),
             search
         };
         if (filter) params.filter = filter;
+        if (near) Object.assign(params, nearParams(near));
 
         const queryParams = new URLSearchParams(params);
 
@@ -148,6 +395,48 @@ export const api = {
         return res.json();
     },
 
+    async getClubVenues(clubId: string): Promise<Venue[]> {
+        const res = await fetch(`${API_URL}/clubs/${clubId}/venues`);
+        if (!res.ok) throw new Error('Failed to fetch venues');
+        return res.json();
+    },
+
+    async getClubSeries(clubId: string): Promise<MatchSeries[]> {
+        const res = await fetch(`${API_URL}/clubs/${clubId}/series`);
+        if (!res.ok) throw new Error('Failed to fetch series');
+        return res.json();
+    },
+
+    // Auto-book the given position into every match of the series
+    async subscribeSeries(seriesId: string, position: string): Promise<any> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/series/${seriesId}/subscribe`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+            body: JSON.stringify({ position }),
+        });
+        if (!res.ok) {
+            const err = await res.json().catch(() => null);
+            throw new Error(err?.error || 'Failed to subscribe to series');
+        }
+        return res.json();
+    },
+
+    async unsubscribeSeries(seriesId: string): Promise<any> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/series/${seriesId}/subscribe`, {
+            method: 'DELETE',
+            headers: {
+                'Authorization': `Bearer ${token}`
+            },
+        });
+        if (!res.ok) throw new Error('Failed to unsubscribe from series');
+        return res.json();
+    },
+
     async createClub(data: { name: string; description?: string; logo?: string }): Promise<Club> {
         const token = await getToken();
         const res = await fetch(`${API_URL}/clubs`, {
@@ -187,15 +476,15 @@ export const api = {
     },
 
 
-    async getClubAnnouncements(clubId: string): Promise<any[]> {
-        const res = await fetch(`${API_URL}/clubs/${clubId}/announcements`);
+    async getClubAnnouncements(clubId: string, cursor: string = ''): Promise<Page<any>> {
+        const res = await fetch(`${API_URL}/clubs/${clubId}/announcements?cursor=${encodeURIComponent(cursor)}`);
         if (!res.ok) throw new Error('Failed to fetch announcements');
         return res.json();
     },
 
-    async getAnnouncementsForOwner(clubId: string): Promise<any[]> {
+    async getAnnouncementsForOwner(clubId: string, cursor: string = ''): Promise<Page<any>> {
         const token = await getToken();
-        const res = await fetch(`${API_URL}/clubs/${clubId}/announcements/manage`, {
+        const res = await fetch(`${API_URL}/clubs/${clubId}/announcements/manage?cursor=${encodeURIComponent(cursor)}`, {
             headers: { 'Authorization': `Bearer ${token}` }
         });
         if (!res.ok) throw new Error('Failed to fetch management announcements');
@@ -216,7 +505,7 @@ export const api = {
         return res.json();
     },
 
-    async updateClub(id: string, data: { name?: string; description?: string; logo?: string; social_media?: string }): Promise<Club> {
+    async updateClub(id: string, data: { name?: string; description?: string; logo?: string; social_media?: string; team_setup?: TeamSetup }): Promise<Club> {
         const token = await getToken();
         const res = await fetch(`${API_URL}/clubs/${id}`, {
             method: 'PUT',
@@ -305,9 +594,9 @@ export const api = {
         return res.json();
     },
 
-    async getNotifications(): Promise<any[]> {
+    async getNotifications(cursor: string = ''): Promise<Page<any>> {
         const token = await getToken();
-        const res = await fetch(`${API_URL}/notifications`, {
+        const res = await fetch(`${API_URL}/notifications?cursor=${encodeURIComponent(cursor)}`, {
             headers: { 'Authorization': `Bearer ${token}` }
         });
         if (!res.ok) throw new Error('Failed to fetch notifications');
@@ -391,6 +680,35 @@ export const api = {
         return res.json();
     },
 
+    // Copies the match as a new draft, one week later unless date/time are given
+    async cloneMatch(id: string, overrides: { date?: string; time?: string; title?: string; status?: 'draft' | 'published' } = {}): Promise<Match> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${id}/clone`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+            body: JSON.stringify(overrides),
+        });
+        if (!res.ok) {
+            const err = await res.json().catch(() => null);
+            throw new Error(err?.error || 'Failed to clone match');
+        }
+        return res.json();
+    },
+
+    async getMatchTemplates(clubId: string): Promise<any[]> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/clubs/${clubId}/templates`, {
+            headers: {
+                'Authorization': `Bearer ${token}`
+            },
+        });
+        if (!res.ok) throw new Error('Failed to fetch templates');
+        return res.json();
+    },
+
     async joinMatch(matchId: string, position: string = 'player_front'): Promise<any> {
         const token = await getToken();
         const res = await fetch(`${API_URL}/bookings`, {
@@ -423,16 +741,54 @@ export const api = {
         return res.json();
     },
 
-    async cancelBooking(bookingId: string): Promise<any> {
+    async cancelBooking(bookingId: string, reason: string = ''): Promise<any> {
         const token = await getToken();
         const res = await fetch(`${API_URL}/bookings/${bookingId}`, {
             method: 'DELETE',
             headers: {
                 'Content-Type': 'application/json',
                 'Authorization': `Bearer ${token}`
-            }
+            },
+            body: JSON.stringify({ reason }),
+        });
+        if (!res.ok) {
+            // Cancellation may be refused by the club's cancellation policy
+            const err = await res.json().catch(() => null);
+            throw new Error(err?.error || 'Failed to cancel booking');
+        }
+        return res.json(); // { message, cancellation: { rule, fee, refund, ... } }
+    },
+
+    // Waitlist offer: the freed spot is held until the booking's offer_expires_at
+    async acceptOffer(bookingId: string): Promise<any> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/bookings/${bookingId}/offer/accept`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
         });
-        if (!res.ok) throw new Error('Failed to cancel booking');
+        if (!res.ok) {
+            const err = await res.json().catch(() => null);
+            throw new Error(err?.error || 'Failed to accept offer');
+        }
+        return res.json();
+    },
+
+    async declineOffer(bookingId: string): Promise<any> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/bookings/${bookingId}/offer/decline`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+        });
+        if (!res.ok) {
+            const err = await res.json().catch(() => null);
+            throw new Error(err?.error || 'Failed to decline offer');
+        }
         return res.json();
     },
 
@@ -530,7 +886,8 @@ export const api = {
         return res.json();
     },
 
-    async updateTeamMember(memberId: string, teamId: string): Promise<any> {
+    // lock pins the player to the team so regenerating keeps them there
+    async updateTeamMember(memberId: string, teamId: string, lock = false): Promise<any> {
         const token = await getToken();
         const res = await fetch(`${API_URL}/teams/members/${memberId}`, {
             method: 'PUT',
@@ -538,26 +895,29 @@ export const api = {
                 'Content-Type': 'application/json',
                 'Authorization': `Bearer ${token}`
             },
-            body: JSON.stringify({ team_id: teamId }),
+            body: JSON.stringify({ team_id: teamId, lock }),
         });
         if (!res.ok) throw new Error('Failed to update team member');
         return res.json();
     },
 
+    // The published lineup; organisers see drafts through getLineups
     async getTeams(matchId: string): Promise<any> {
         const res = await fetch(`${API_URL}/matches/${matchId}/teams`);
         if (!res.ok) throw new Error('Failed to fetch teams');
         return res.json();
     },
 
-    async generateTeams(matchId: string): Promise<any> {
+    // Pass the seed of an earlier split to generate it again
+    async generateTeams(matchId: string, options: TeamSetup & { seed?: number } = {}): Promise<TeamSplit> {
         const token = await getToken();
         const res = await fetch(`${API_URL}/matches/${matchId}/teams/generate`, {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
                 'Authorization': `Bearer ${token}`
-            }
+            },
+            body: JSON.stringify(options)
         });
         if (!res.ok) {
             const err = await res.json();
@@ -566,6 +926,175 @@ export const api = {
         return res.json();
     },
 
+    async getMatchRatings(matchId: string): Promise<PlayerRating[]> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/ratings`, {
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to fetch ratings');
+        return res.json();
+    },
+
+    async setPlayerRating(matchId: string, userId: string, rating: number): Promise<PlayerRating> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/ratings/${userId}`, {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+            body: JSON.stringify({ rating })
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(err.error || 'Failed to save rating');
+        }
+        return res.json();
+    },
+
+    async getLineups(matchId: string): Promise<TeamLineup[]> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/lineups`, {
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to fetch lineups');
+        return res.json();
+    },
+
+    async getLineup(matchId: string, version: number): Promise<TeamLineup> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/lineups/${version}`, {
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to fetch lineup');
+        return res.json();
+    },
+
+    async diffLineups(matchId: string, from: number, to: number): Promise<LineupDiff> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/lineups/diff?from=${from}&to=${to}`, {
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to compare lineups');
+        return res.json();
+    },
+
+    // Notifies every player in the lineup
+    async publishLineup(matchId: string, version: number): Promise<TeamLineup> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/lineups/${version}/publish`, {
+            method: 'POST',
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(err.error || 'Failed to publish lineup');
+        }
+        return res.json();
+    },
+
+    // Copies the version into a new draft
+    async rollbackLineup(matchId: string, version: number): Promise<TeamLineup> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/lineups/${version}/rollback`, {
+            method: 'POST',
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(err.error || 'Failed to roll back lineup');
+        }
+        return res.json();
+    },
+
+    // Public: the games played inside the match and the standings
+    async getMatchResults(matchId: string): Promise<MatchResults> {
+        const res = await fetch(`${API_URL}/matches/${matchId}/results`);
+        if (!res.ok) throw new Error('Failed to fetch results');
+        return res.json();
+    },
+
+    // Between the teams of the published lineup; legs for round robin, games (0 = open-ended) for winner stays on
+    async createFixtures(matchId: string, data: { format: FixtureFormat; legs?: number; games?: number }): Promise<MatchResults> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/fixtures`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+            body: JSON.stringify(data)
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(err.error || 'Failed to create fixtures');
+        }
+        return res.json();
+    },
+
+    async recordFixtureResult(matchId: string, fixtureId: string, homeScore: number, awayScore: number): Promise<MatchResults> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/fixtures/${fixtureId}/result`, {
+            method: 'PUT',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+            body: JSON.stringify({ home_score: homeScore, away_score: awayScore })
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(err.error || 'Failed to save result');
+        }
+        return res.json();
+    },
+
+    async deleteFixtures(matchId: string): Promise<any> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/fixtures`, {
+            method: 'DELETE',
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to delete fixtures');
+        return res.json();
+    },
+
+    async getTeamConstraints(matchId: string): Promise<TeamConstraint[]> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/team-constraints`, {
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to fetch team constraints');
+        return res.json();
+    },
+
+    async createTeamConstraint(matchId: string, data: { kind: ConstraintKind; user_id: string; other_user_id?: string; slot?: number }): Promise<TeamConstraint> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/team-constraints`, {
+            method: 'POST',
+            headers: {
+                'Content-Type': 'application/json',
+                'Authorization': `Bearer ${token}`
+            },
+            body: JSON.stringify(data)
+        });
+        if (!res.ok) {
+            const err = await res.json();
+            throw new Error(err.error || 'Failed to save team constraint');
+        }
+        return res.json();
+    },
+
+    async deleteTeamConstraint(matchId: string, constraintId: string): Promise<any> {
+        const token = await getToken();
+        const res = await fetch(`${API_URL}/matches/${matchId}/team-constraints/${constraintId}`, {
+            method: 'DELETE',
+            headers: { 'Authorization': `Bearer ${token}` }
+        });
+        if (!res.ok) throw new Error('Failed to delete team constraint');
+        return res.json();
+    },
+
     async login(provider: string = 'google', token: string = 'dummy', email?: string, name?: string, password?: string): Promise<{ token: string, user: any }> {
         const body: any = { provider, token, email, name };
         if (password) {
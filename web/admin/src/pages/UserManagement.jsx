@@ -10,7 +10,7 @@ export default function UserManagement() {
     const [showRoleModal, setShowRoleModal] = useState(false);
     const [editingUser, setEditingUser] = useState(null);
     const [formData, setFormData] = useState({ name: '', email: '', password: '', phone: '' });
-    const [roleForm, setRoleForm] = useState({ role: 'user' });
+    const [roleForm, setRoleForm] = useState({ role: 'player' });
 
     useEffect(() => {
         fetchUsers();
@@ -18,8 +18,15 @@ export default function UserManagement() {
 
     const fetchUsers = async () => {
         try {
-            const res = await api.get('/admin/users');
-            setUsers(res.data || []);
+            // Search below is client-side, so follow the cursor through every page
+            let all = [];
+            let cursor = '';
+            do {
+                const res = await api.get('/admin/users', { params: { cursor, limit: 100 } });
+                all = all.concat(res.data.items || []);
+                cursor = res.data.next_cursor;
+            } while (cursor);
+            setUsers(all);
         } catch (err) {
             console.error(err);
         } finally {
@@ -41,7 +48,7 @@ export default function UserManagement() {
 
     const openRoleModal = (user) => {
         setEditingUser(user);
-        setRoleForm({ role: user.role || 'user' });
+        setRoleForm({ role: user.role || 'player' });
         setShowRoleModal(true);
     };
 
@@ -145,7 +152,7 @@ export default function UserManagement() {
                             value={roleForm.role}
                             onChange={(e) => setRoleForm({ role: e.target.value })}
                         >
-                            <option value="user">User</option>
+                            <option value="player">Player</option>
                             <option value="admin">Admin</option>
                         </select>
                     </div>
@@ -185,7 +192,7 @@ export default function UserManagement() {
                                             backgroundColor: user.role === 'admin' ? '#dc2626' : '#059669',
                                             color: 'white'
                                         }}>
-                                            {user.role || 'user'}
+                                            {user.role || 'player'}
                                         </span>
                                     </td>
                                     <td>